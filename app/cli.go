@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"app/internal/entities"
+	userRepository "app/internal/repositories/user"
+	usecases "app/internal/usecases"
+)
+
+// runCommand executes a maintenance subcommand against the configured database
+func runCommand(args []string, userRepo userRepository.UserRepository, userUsecase usecases.UserUsecase) error {
+	switch args[0] {
+	case "promote":
+		fs := flag.NewFlagSet("promote", flag.ContinueOnError)
+		email := fs.String("email", "", "email of the user to promote")
+		role := fs.String("role", "admin", "role to grant: user, moderator or admin")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *email == "" {
+			return fmt.Errorf("-email is required")
+		}
+
+		ctx := context.Background()
+		user, err := userRepo.FindByEmail(ctx, *email)
+		if err != nil {
+			return err
+		}
+		user, err = userUsecase.UpdateRole(ctx, &entities.UpdateRoleRequest{UserID: user.ID, Role: *role})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s is now %s\n", user.Email, user.Role)
+		return nil
+	case "unlock":
+		fs := flag.NewFlagSet("unlock", flag.ContinueOnError)
+		email := fs.String("email", "", "email of the user to unlock")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *email == "" {
+			return fmt.Errorf("-email is required")
+		}
+
+		ctx := context.Background()
+		user, err := userRepo.FindByEmail(ctx, *email)
+		if err != nil {
+			return err
+		}
+		if err := userUsecase.Unlock(ctx, user.ID); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s is unlocked\n", user.Email)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,51 @@
+package commons
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognise and scan for
+const APIKeyPrefix = "blog_"
+
+// APIKeyValidator resolves an API key to the user it acts for
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (Principal, error)
+}
+
+// APIKeyMiddleware works like JWTMiddleware but also accepts `Authorization: ApiKey <key>`
+// for keys granted the scope. Routes that manage credentials should keep using JWTMiddleware,
+// so a leaked key can not be used to create more keys or take over the account.
+func (jwtConf *ConfigJWT) APIKeyMiddleware(scope string, next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if tokenStr, ok := strings.CutPrefix(header, "Bearer "); ok && tokenStr != "" {
+			principal, err := jwtConf.authenticateToken(r.Context(), tokenStr)
+			if err != nil {
+				ErrorResponse(w, http.StatusUnauthorized, err)
+				return
+			}
+			next(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+			return
+		}
+
+		key, ok := strings.CutPrefix(header, "ApiKey ")
+		if !ok || key == "" || jwtConf.APIKeys == nil {
+			ErrorResponse(w, http.StatusUnauthorized, errors.New("Token required"))
+			return
+		}
+
+		principal, err := jwtConf.APIKeys.ValidateAPIKey(r.Context(), key)
+		if err != nil {
+			ErrorResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+		if !principal.HasScope(scope) {
+			ErrorResponse(w, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		next(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
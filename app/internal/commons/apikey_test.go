@@ -0,0 +1,64 @@
+package commons
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type apiKeyValidatorFunc func(ctx context.Context, key string) (Principal, error)
+
+func (f apiKeyValidatorFunc) ValidateAPIKey(ctx context.Context, key string) (Principal, error) {
+	return f(ctx, key)
+}
+
+func TestConfigJWT_APIKeyMiddleware(t *testing.T) {
+	conf := ConfigJWT{SecretJWT: "secret", ExpiresDuration: 1}
+	conf.APIKeys = apiKeyValidatorFunc(func(ctx context.Context, key string) (Principal, error) {
+		if key != "blog_valid" {
+			return Principal{}, ErrInvalidAPIKey
+		}
+		return Principal{UserID: 1, APIKeyID: 3, Scopes: ScopePostsWrite}, nil
+	})
+	access, _ := conf.GenerateJWT(Principal{UserID: 1, Role: RoleUser, SessionID: 7})
+
+	tests := []struct {
+		name       string
+		scope      string
+		header     string
+		wantStatus int
+	}{
+		{name: "access token", scope: ScopeCommentsWrite, header: "Bearer " + access, wantStatus: http.StatusOK},
+		{name: "api key with scope", scope: ScopePostsWrite, header: "ApiKey blog_valid", wantStatus: http.StatusOK},
+		{name: "api key without scope", scope: ScopeCommentsWrite, header: "ApiKey blog_valid", wantStatus: http.StatusForbidden},
+		{name: "unknown api key", scope: ScopePostsWrite, header: "ApiKey blog_unknown", wantStatus: http.StatusUnauthorized},
+		{name: "missing credentials", scope: ScopePostsWrite, header: "", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := conf.APIKeyMiddleware(tt.scope, func(w http.ResponseWriter, r *http.Request) {})
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("ConfigJWT.APIKeyMiddleware() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+
+	// API keys must not work on routes that only take session tokens
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "ApiKey blog_valid")
+	rec := httptest.NewRecorder()
+	conf.JWTMiddleware(func(w http.ResponseWriter, r *http.Request) {})(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("ConfigJWT.JWTMiddleware() accepted an api key, status = %d", rec.Code)
+	}
+}
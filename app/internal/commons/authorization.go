@@ -0,0 +1,103 @@
+package commons
+
+import (
+	"net/http"
+)
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+type Permission string
+
+const (
+	PermUpdateAnyPost    Permission = "posts:update:any"
+	PermDeleteAnyPost    Permission = "posts:delete:any"
+	PermDeleteAnyComment Permission = "comments:delete:any"
+	PermManageUsers      Permission = "users:manage"
+	PermManageCategories Permission = "categories:manage"
+)
+
+// Scopes limit what an API key may do on behalf of its owner
+const (
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsWrite = "comments:write"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleUser:      {},
+	RoleModerator: {PermDeleteAnyComment},
+	RoleAdmin:     {PermUpdateAnyPost, PermDeleteAnyPost, PermDeleteAnyComment, PermManageUsers, PermManageCategories},
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether the role is granted the permission
+func HasPermission(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission rejects requests whose token role lacks the permission. It has to be
+// wrapped by JWTMiddleware, which puts the caller into the request context.
+func RequirePermission(perm Permission, next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := PrincipalFromContext(r.Context())
+		if !HasPermission(principal.Role, perm) {
+			ErrorResponse(w, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// IsOwnerOrPermitted reports whether the principal owns a resource, or is granted perm over
+// those of others, like admins editing any post or moderators deleting any comment
+func IsOwnerOrPermitted(principal Principal, ownerID uint, perm Permission) bool {
+	if principal.UserID == 0 {
+		return false
+	}
+	return ownerID == principal.UserID || HasPermission(principal.Role, perm)
+}
+
+// OwnerFunc returns the ID of the user owning the resource a request is about, or
+// ErrNotFound when the caller may not know it exists
+type OwnerFunc func(r *http.Request) (uint, error)
+
+// RequireOwnerOrPermission only lets the owner of the resource through, and others whose
+// role is granted perm. Like RequirePermission it has to be wrapped by JWTMiddleware or
+// APIKeyMiddleware.
+func RequireOwnerOrPermission(perm Permission, owner OwnerFunc, next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			ErrorResponse(w, http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+		ownerID, err := owner(r)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if err == ErrBadRequest {
+				status = http.StatusBadRequest
+			} else if err == ErrNotFound {
+				status = http.StatusNotFound
+			}
+			ErrorResponse(w, status, err)
+			return
+		}
+		if !IsOwnerOrPermitted(principal, ownerID, perm) {
+			ErrorResponse(w, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		next(w, r)
+	})
+}
@@ -0,0 +1,46 @@
+package commons
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireOwnerOrPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *Principal
+		ownerErr   error
+		wantStatus int
+	}{
+		{name: "owner", principal: &Principal{UserID: 1, Role: RoleUser}, wantStatus: http.StatusOK},
+		{name: "someone else", principal: &Principal{UserID: 2, Role: RoleUser}, wantStatus: http.StatusForbidden},
+		{name: "moderator", principal: &Principal{UserID: 2, Role: RoleModerator}, wantStatus: http.StatusOK},
+		{name: "admin", principal: &Principal{UserID: 2, Role: RoleAdmin}, wantStatus: http.StatusOK},
+		{name: "not found", principal: &Principal{UserID: 2, Role: RoleAdmin}, ownerErr: ErrNotFound, wantStatus: http.StatusNotFound},
+		{name: "unauthenticated", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner := func(r *http.Request) (uint, error) {
+				return 1, tt.ownerErr
+			}
+			next := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}
+
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = WithPrincipal(ctx, *tt.principal)
+			}
+			req := httptest.NewRequest(http.MethodDelete, "/posts/1/comments/2", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+			RequireOwnerOrPermission(PermDeleteAnyComment, owner, next)(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("RequireOwnerOrPermission() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
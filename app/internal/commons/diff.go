@@ -0,0 +1,94 @@
+package commons
+
+import (
+	"app/internal/entities"
+	"strings"
+)
+
+// maxDiffCells bounds the table of the line diff. Larger changes are shown as every old
+// line deleted and every new line inserted.
+const maxDiffCells = 4_000_000
+
+// DiffLines compares a and b line by line. It returns the lines of both in order, each
+// marked as kept, deleted from a or inserted from b.
+func DiffLines(a, b string) []entities.DiffLine {
+	x, y := splitLines(a), splitLines(b)
+	diff := []entities.DiffLine{}
+
+	// Lines shared at both ends need no table
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+	for _, line := range x[:prefix] {
+		diff = append(diff, entities.DiffLine{Op: entities.DiffEqual, Text: line})
+	}
+	diff = append(diff, diffMiddle(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, line := range x[len(x)-suffix:] {
+		diff = append(diff, entities.DiffLine{Op: entities.DiffEqual, Text: line})
+	}
+	return diff
+}
+
+// diffMiddle diffs x and y through their longest common subsequence of lines
+func diffMiddle(x, y []string) []entities.DiffLine {
+	var diff []entities.DiffLine
+	if (len(x)+1)*(len(y)+1) > maxDiffCells {
+		for _, line := range x {
+			diff = append(diff, entities.DiffLine{Op: entities.DiffDelete, Text: line})
+		}
+		for _, line := range y {
+			diff = append(diff, entities.DiffLine{Op: entities.DiffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			diff = append(diff, entities.DiffLine{Op: entities.DiffEqual, Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, entities.DiffLine{Op: entities.DiffDelete, Text: x[i]})
+			i++
+		default:
+			diff = append(diff, entities.DiffLine{Op: entities.DiffInsert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		diff = append(diff, entities.DiffLine{Op: entities.DiffDelete, Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		diff = append(diff, entities.DiffLine{Op: entities.DiffInsert, Text: y[j]})
+	}
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,33 @@
+package commons
+
+import (
+	"app/internal/entities"
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	eq := func(s string) entities.DiffLine { return entities.DiffLine{Op: entities.DiffEqual, Text: s} }
+	ins := func(s string) entities.DiffLine { return entities.DiffLine{Op: entities.DiffInsert, Text: s} }
+	del := func(s string) entities.DiffLine { return entities.DiffLine{Op: entities.DiffDelete, Text: s} }
+
+	tests := []struct {
+		name string
+		a, b string
+		want []entities.DiffLine
+	}{
+		{name: "same", a: "a\nb", b: "a\nb", want: []entities.DiffLine{eq("a"), eq("b")}},
+		{name: "changed line", a: "a\nb\nc", b: "a\nB\nc", want: []entities.DiffLine{eq("a"), del("b"), ins("B"), eq("c")}},
+		{name: "moved lines", a: "a\nb\nc\nd", b: "b\nc\na\nd", want: []entities.DiffLine{del("a"), eq("b"), eq("c"), ins("a"), eq("d")}},
+		{name: "from empty", a: "", b: "a\r\nb", want: []entities.DiffLine{ins("a"), ins("b")}},
+		{name: "both empty", a: "", b: "", want: []entities.DiffLine{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
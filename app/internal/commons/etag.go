@@ -0,0 +1,25 @@
+package commons
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ETag formats the version of a resource as a strong entity tag, e.g. "3"
+func ETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// ParseETag returns the version in an entity tag made by ETag. Weak tags never match an
+// If-Match header, so they are rejected like malformed ones.
+func ParseETag(tag string) (uint, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 32)
+	if err != nil || version == 0 {
+		return 0, false
+	}
+	return uint(version), true
+}
@@ -0,0 +1,27 @@
+package commons
+
+import "testing"
+
+func TestParseETag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   uint
+		wantOK bool
+	}{
+		{tag: ETag(3), want: 3, wantOK: true},
+		{tag: ` "12" `, want: 12, wantOK: true},
+		{tag: `W/"3"`},
+		{tag: `3`},
+		{tag: `"0"`},
+		{tag: `"abc"`},
+		{tag: `*`},
+		{tag: ``},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseETag(tt.tag)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseETag(%q) = %d, %v, want %d, %v", tt.tag, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
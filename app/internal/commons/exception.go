@@ -3,15 +3,45 @@ package commons
 import "errors"
 
 var (
-	ErrInvalidCredentials  = errors.New("invalid credentials")
-	ErrUnauthorized        = errors.New("unauthorized")
-	ErrForbidden           = errors.New("forbidden")
-	ErrInternalServerError = errors.New("internal server error")
-	ErrNotFound            = errors.New("not found")
-	ErrValidationFailed    = errors.New("validation failed")
-	ErrBadRequest          = errors.New("invalid request message")
-	ErrTimeout             = errors.New("operation timeout")
-	ErrUserAlreadyExists   = errors.New("user already exists")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrPostNotFound        = errors.New("post not found")
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden")
+	ErrInternalServerError  = errors.New("internal server error")
+	ErrNotFound             = errors.New("not found")
+	ErrValidationFailed     = errors.New("validation failed")
+	ErrBadRequest           = errors.New("invalid request message")
+	ErrTimeout              = errors.New("operation timeout")
+	ErrUserAlreadyExists    = errors.New("user already exists")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrPostNotFound         = errors.New("post not found")
+	ErrSessionRevoked       = errors.New("session revoked or expired")
+	ErrInvalidResetToken    = errors.New("invalid or expired reset token")
+	ErrEmailNotVerified     = errors.New("email not verified")
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	ErrInvalidVerifyToken   = errors.New("invalid or expired verification token")
+	ErrTooManyAttempts      = errors.New("too many login attempts, try again later")
+	ErrAccountLocked        = errors.New("account temporarily locked")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrollment has not been started")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("invalid or expired login challenge")
+	ErrUnknownPasswordHash  = errors.New("unknown password hash format")
+	ErrInvalidAPIKey        = errors.New("invalid, expired or revoked api key")
+	ErrInvalidOIDCState     = errors.New("invalid or expired sign-in state")
+	ErrOIDCLoginFailed      = errors.New("sign-in with the identity provider failed")
+	ErrOIDCAccountConflict  = errors.New("an account with this email already exists and the provider did not verify the email")
+	ErrInvalidPostStatus    = errors.New("invalid post status")
+	ErrInvalidPublishTime   = errors.New("publish time must be in the future")
+	ErrInvalidTagName       = errors.New("tag and category names need at least one letter or digit")
+	ErrUnknownCategory      = errors.New("unknown category")
+	ErrCategoryExists       = errors.New("a category with this name already exists")
+	ErrVersionRequired      = errors.New("the If-Match header with the ETag of the post is required")
+	ErrVersionMismatch      = errors.New("the post was changed by someone else, reload it and try again")
+	ErrUnsupportedPatch     = errors.New("patches must be sent as application/merge-patch+json")
+	ErrMediaTooLarge        = errors.New("the file is larger than the upload limit")
+	ErrMediaQuotaExceeded   = errors.New("the upload would exceed your storage quota")
+	ErrUnsupportedMediaType = errors.New("only JPEG, PNG, GIF and WebP images can be uploaded")
+	ErrMediaInUse           = errors.New("the media is the cover of a post")
+	ErrInvalidCover         = errors.New("the cover must be an image you uploaded")
 )
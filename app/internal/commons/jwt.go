@@ -4,56 +4,232 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 )
 
+// SessionValidator reports whether the session an access token was issued for is still usable
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, sessionID uint) error
+}
+
 type ConfigJWT struct {
-	SecretJWT       string
+	// SigningMethod is HS256 (signed with SecretJWT), RS256 or EdDSA (signed with Keys)
+	SigningMethod string
+	SecretJWT     string
+	Keys          *KeyManager
+	Issuer        string
+	Audience      string
+	// ExpiresDuration is the access token lifetime in minutes
 	ExpiresDuration int
+	// RefreshExpiresDuration is the refresh token lifetime in hours
+	RefreshExpiresDuration int
+	Sessions               SessionValidator
+	APIKeys                APIKeyValidator
 }
 
-func (jwtConf *ConfigJWT) GenerateJWT(email string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"email": email,
-		"exp":   time.Now().Add(time.Hour * time.Duration(jwtConf.ExpiresDuration)).Unix(),
+func (jwtConf *ConfigJWT) AccessTokenTTL() time.Duration {
+	return time.Minute * time.Duration(jwtConf.ExpiresDuration)
+}
+
+func (jwtConf *ConfigJWT) RefreshTokenTTL() time.Duration {
+	return time.Hour * time.Duration(jwtConf.RefreshExpiresDuration)
+}
+
+func (jwtConf *ConfigJWT) GenerateJWT(p Principal) (string, error) {
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	return jwtConf.sign(jwt.MapClaims{
+		"typ":            "access",
+		"sub":            strconv.FormatUint(uint64(p.UserID), 10),
+		"name":           p.Name,
+		"email":          p.Email,
+		"email_verified": p.EmailVerified,
+		"role":           p.Role,
+		"sid":            strconv.FormatUint(uint64(p.SessionID), 10),
+		"iss":            jwtConf.Issuer,
+		"aud":            jwtConf.Audience,
+		"jti":            jti,
+		"iat":            now.Unix(),
+		"exp":            now.Add(jwtConf.AccessTokenTTL()).Unix(),
 	})
-	return token.SignedString([]byte(jwtConf.SecretJWT))
+}
+
+// principalFromClaims converts the claims of a verified access token into a Principal
+func principalFromClaims(claims jwt.MapClaims) (Principal, error) {
+	if typ, _ := claims["typ"].(string); typ != "access" {
+		return Principal{}, ErrUnauthorized
+	}
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseUint(sub, 10, 32)
+	if err != nil {
+		return Principal{}, ErrUnauthorized
+	}
+	sid, _ := claims["sid"].(string)
+	sessionID, err := strconv.ParseUint(sid, 10, 32)
+	if err != nil {
+		return Principal{}, ErrUnauthorized
+	}
+
+	p := Principal{UserID: uint(userID), SessionID: uint(sessionID)}
+	p.Name, _ = claims["name"].(string)
+	p.Email, _ = claims["email"].(string)
+	p.Role, _ = claims["role"].(string)
+	p.EmailVerified, _ = claims["email_verified"].(bool)
+	return p, nil
+}
+
+// GeneratePurposeJWT signs a short-lived token that is only accepted for a single purpose,
+// such as an email verification link. It can never be used as an access token.
+func (jwtConf *ConfigJWT) GeneratePurposeJWT(purpose, subject string, ttl time.Duration) (string, error) {
+	return jwtConf.GeneratePurposeJWTWithClaims(purpose, subject, nil, ttl)
+}
+
+// GeneratePurposeJWTWithClaims is GeneratePurposeJWT with extra string claims. The claims
+// are signed but readable by whoever holds the token, so they must not be secret to them.
+func (jwtConf *ConfigJWT) GeneratePurposeJWTWithClaims(purpose, subject string, extra map[string]string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	claims["purpose"] = purpose
+	claims["sub"] = subject
+	claims["iss"] = jwtConf.Issuer
+	claims["aud"] = jwtConf.Audience
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	return jwtConf.sign(claims)
+}
+
+// ParsePurposeJWT validates a token created by GeneratePurposeJWT and returns its subject
+func (jwtConf *ConfigJWT) ParsePurposeJWT(purpose, tokenStr string) (string, error) {
+	claims, err := jwtConf.ParsePurposeJWTClaims(purpose, tokenStr)
+	if err != nil {
+		return "", err
+	}
+	return claims["sub"].(string), nil
+}
+
+// ParsePurposeJWTClaims validates a purpose token and returns all of its claims
+func (jwtConf *ConfigJWT) ParsePurposeJWTClaims(purpose, tokenStr string) (jwt.MapClaims, error) {
+	claims, err := jwtConf.ExtractClaims(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if p, _ := claims["purpose"].(string); p != purpose {
+		return nil, ErrUnauthorized
+	}
+	if subject, _ := claims["sub"].(string); subject == "" {
+		return nil, ErrUnauthorized
+	}
+	return claims, nil
+}
+
+// sign signs the claims with the configured method, asymmetric tokens carry the kid of their key
+func (jwtConf *ConfigJWT) sign(claims jwt.MapClaims) (string, error) {
+	if jwtConf.signingMethod() == SigningAlgHS256 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtConf.SecretJWT))
+	}
+
+	key := jwtConf.Keys.Current()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+func (jwtConf *ConfigJWT) signingMethod() string {
+	if jwtConf.SigningMethod == "" {
+		return SigningAlgHS256
+	}
+	return jwtConf.SigningMethod
+}
+
+func (jwtConf *ConfigJWT) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{jwtConf.signingMethod()}), jwt.WithIssuedAt()}
+	if jwtConf.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(jwtConf.Issuer))
+	}
+	if jwtConf.Audience != "" {
+		opts = append(opts, jwt.WithAudience(jwtConf.Audience))
+	}
+	return opts
 }
 
 func (jwtConf *ConfigJWT) ExtractClaims(tokenStr string) (jwt.MapClaims, error) {
+	method := jwtConf.signingMethod()
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtConf.SecretJWT), nil
-	})
+		if method == SigningAlgHS256 {
+			return []byte(jwtConf.SecretJWT), nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := jwtConf.Keys.Find(kid)
+		if !ok || key.Algorithm != token.Method.Alg() {
+			return nil, ErrUnauthorized
+		}
+		return key.Public(), nil
+	}, jwtConf.parserOptions()...)
 	if err != nil {
 		return nil, err
 	}
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		return claims, nil
 	}
-	return nil, err
+	return nil, ErrUnauthorized
 }
 
 // create middleware to check token for request without framework gin
 func (jwtConf *ConfigJWT) JWTMiddleware(next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header["Authorization"] != nil {
-			tokenStr := r.Header["Authorization"][0]
-			tokenStr = strings.Split(tokenStr, "Bearer ")[1]
-			claims, err := jwtConf.ExtractClaims(tokenStr)
-			if err != nil {
-				ErrorResponse(w, http.StatusUnauthorized, err)
-				return
-			}
-
-			// convert claims to context
-			ctx := context.WithValue(r.Context(), "user", claims["email"])
-			next(w, r.WithContext(ctx))
-		} else {
+		tokenStr, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || tokenStr == "" {
 			ErrorResponse(w, http.StatusUnauthorized, errors.New("Token required"))
 			return
 		}
+
+		principal, err := jwtConf.authenticateToken(r.Context(), tokenStr)
+		if err != nil {
+			ErrorResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		next(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// OptionalJWTMiddleware lets anonymous requests through, but authenticates the caller when
+// a token is sent, so public endpoints can show more to signed-in users
+func (jwtConf *ConfigJWT) OptionalJWTMiddleware(next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next(w, r)
+			return
+		}
+		jwtConf.JWTMiddleware(next)(w, r)
 	})
 }
+
+// authenticateToken verifies an access token and checks that its session is still active
+func (jwtConf *ConfigJWT) authenticateToken(ctx context.Context, tokenStr string) (Principal, error) {
+	claims, err := jwtConf.ExtractClaims(tokenStr)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	principal, err := principalFromClaims(claims)
+	if err != nil {
+		return Principal{}, err
+	}
+	if jwtConf.Sessions != nil {
+		if err := jwtConf.Sessions.ValidateSession(ctx, principal.SessionID); err != nil {
+			return Principal{}, err
+		}
+	}
+	return principal, nil
+}
@@ -0,0 +1,182 @@
+package commons
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestConfigJWT_AsymmetricRoundTrip(t *testing.T) {
+	for _, alg := range []string{SigningAlgRS256, SigningAlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			keys, err := NewKeyManager(NewFileKeyStore(t.TempDir()), alg, time.Hour, time.Hour)
+			if err != nil {
+				t.Fatalf("NewKeyManager() error = %v", err)
+			}
+			conf := ConfigJWT{SigningMethod: alg, Keys: keys, ExpiresDuration: 1}
+
+			token, err := conf.GenerateJWT(Principal{UserID: 1, Email: "john@example.com", Role: RoleUser, SessionID: 1})
+			if err != nil {
+				t.Fatalf("ConfigJWT.GenerateJWT() error = %v", err)
+			}
+			claims, err := conf.ExtractClaims(token)
+			if err != nil {
+				t.Fatalf("ConfigJWT.ExtractClaims() error = %v", err)
+			}
+			if claims["email"] != "john@example.com" {
+				t.Errorf("ConfigJWT.ExtractClaims() email = %v, want %v", claims["email"], "john@example.com")
+			}
+
+			jwks := conf.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != keys.Current().ID || jwks.Keys[0].Alg != alg {
+				t.Errorf("ConfigJWT.JWKS() = %+v, want the current %s key", jwks, alg)
+			}
+		})
+	}
+}
+
+func TestConfigJWT_RejectsOtherSigningMethods(t *testing.T) {
+	keys, err := NewKeyManager(NewFileKeyStore(t.TempDir()), SigningAlgRS256, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	conf := ConfigJWT{SigningMethod: SigningAlgRS256, SecretJWT: "secret", Keys: keys}
+
+	// A token signed with the shared secret must not be accepted once RS256 is configured
+	hs := ConfigJWT{SecretJWT: "secret", ExpiresDuration: 1}
+	token, _ := hs.GenerateJWT(Principal{UserID: 1, Email: "john@example.com", Role: RoleAdmin, SessionID: 1})
+	if _, err := conf.ExtractClaims(token); err == nil {
+		t.Errorf("ConfigJWT.ExtractClaims() accepted an HS256 token")
+	}
+
+	none, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"email": "john@example.com"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := conf.ExtractClaims(none); err == nil {
+		t.Errorf("ConfigJWT.ExtractClaims() accepted an unsigned token")
+	}
+	if _, err := hs.ExtractClaims(none); err == nil {
+		t.Errorf("ConfigJWT.ExtractClaims() with HS256 accepted an unsigned token")
+	}
+}
+
+func TestKeyManager_Rotate(t *testing.T) {
+	store := NewFileKeyStore(t.TempDir())
+	old := SigningKey{ID: "old", Algorithm: SigningAlgEdDSA, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	older := SigningKey{ID: "older", Algorithm: SigningAlgEdDSA, CreatedAt: time.Now().Add(-5 * time.Hour)}
+	for _, k := range []*SigningKey{&old, &older} {
+		m := &KeyManager{algorithm: SigningAlgEdDSA}
+		generated, _ := m.generate(k.CreatedAt)
+		k.Private = generated.Private
+		if err := store.Save(*k); err != nil {
+			t.Fatalf("KeyStore.Save() error = %v", err)
+		}
+	}
+
+	// "old" is past the rotation interval so a new key is generated. "old" stays for
+	// verification while "older" was retired more than the retention ago and is deleted.
+	keys, err := NewKeyManager(store, SigningAlgEdDSA, time.Hour, 90*time.Minute)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+
+	if keys.Current().ID == "old" {
+		t.Errorf("KeyManager.Current() did not rotate the expired key")
+	}
+	if _, ok := keys.Find("old"); !ok {
+		t.Errorf("KeyManager.Find() dropped the previous key before its retention")
+	}
+	if _, ok := keys.Find("older"); ok {
+		t.Errorf("KeyManager.Find() kept a key past its retention")
+	}
+	stored, _ := store.Load()
+	if len(stored) != 2 {
+		t.Errorf("KeyStore.Load() returned %d keys, want 2", len(stored))
+	}
+}
+
+func TestConfigJWT_JWTMiddleware(t *testing.T) {
+	conf := ConfigJWT{SecretJWT: "secret", Issuer: "blog-api", Audience: "blog-api", ExpiresDuration: 1}
+	want := Principal{UserID: 1, Name: "John Doe", Email: "john@example.com", Role: RoleUser, EmailVerified: true, SessionID: 7}
+
+	access, _ := conf.GenerateJWT(want)
+	purpose, _ := conf.GeneratePurposeJWT("verify_email", "john@example.com", time.Hour)
+	otherAudience := conf
+	otherAudience.Audience = "another-service"
+	foreign, _ := otherAudience.GenerateJWT(want)
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "access token", header: "Bearer " + access, wantStatus: http.StatusOK},
+		{name: "missing token", header: "", wantStatus: http.StatusUnauthorized},
+		{name: "other scheme", header: "Basic am9objpzZWNyZXQ=", wantStatus: http.StatusUnauthorized},
+		{name: "purpose token", header: "Bearer " + purpose, wantStatus: http.StatusUnauthorized},
+		{name: "token for another audience", header: "Bearer " + foreign, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Principal
+			handler := conf.JWTMiddleware(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = PrincipalFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("ConfigJWT.JWTMiddleware() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && got != want {
+				t.Errorf("ConfigJWT.JWTMiddleware() principal = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestConfigJWT_OptionalJWTMiddleware(t *testing.T) {
+	conf := ConfigJWT{SecretJWT: "secret", Issuer: "blog-api", Audience: "blog-api", ExpiresDuration: 1}
+	access, _ := conf.GenerateJWT(Principal{UserID: 1, Role: RoleUser})
+
+	tests := []struct {
+		name          string
+		header        string
+		wantStatus    int
+		wantPrincipal bool
+	}{
+		{name: "anonymous", header: "", wantStatus: http.StatusOK},
+		{name: "access token", header: "Bearer " + access, wantStatus: http.StatusOK, wantPrincipal: true},
+		{name: "invalid token", header: "Bearer invalid", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPrincipal bool
+			handler := conf.OptionalJWTMiddleware(func(w http.ResponseWriter, r *http.Request) {
+				_, gotPrincipal = PrincipalFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("ConfigJWT.OptionalJWTMiddleware() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotPrincipal != tt.wantPrincipal {
+				t.Errorf("ConfigJWT.OptionalJWTMiddleware() principal set = %v, want %v", gotPrincipal, tt.wantPrincipal)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package commons
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keyReloadInterval limits how often an unknown kid reloads the key set from the store
+const keyReloadInterval = 10 * time.Second
+
+const (
+	SigningAlgHS256 = "HS256"
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+// SigningKey is one asymmetric key pair identified by its kid
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+func (k SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// KeyStore persists signing keys so every replica signs and verifies with the same set
+type KeyStore interface {
+	Load() ([]SigningKey, error)
+	Save(key SigningKey) error
+	Delete(kid string) error
+}
+
+// fileKeyStore keeps every key as a PKCS#8 PEM file named after its kid
+type fileKeyStore struct {
+	dir string
+}
+
+func NewFileKeyStore(dir string) KeyStore {
+	if dir == "" {
+		dir = "keys"
+	}
+	return &fileKeyStore{dir: dir}
+}
+
+func (s *fileKeyStore) Load() ([]SigningKey, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []SigningKey
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(raw)
+		if block == nil {
+			return nil, fmt.Errorf("%s: no PEM data", file)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		createdAt, err := time.Parse(time.RFC3339, block.Headers["Created"])
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid Created header: %w", file, err)
+		}
+
+		key := SigningKey{
+			ID:        strings.TrimSuffix(filepath.Base(file), ".pem"),
+			CreatedAt: createdAt,
+		}
+		switch k := parsed.(type) {
+		case *rsa.PrivateKey:
+			key.Algorithm, key.Private = SigningAlgRS256, k
+		case ed25519.PrivateKey:
+			key.Algorithm, key.Private = SigningAlgEdDSA, k
+		default:
+			return nil, fmt.Errorf("%s: unsupported key type %T", file, parsed)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *fileKeyStore) Save(key SigningKey) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return err
+	}
+	block := &pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{"Created": key.CreatedAt.UTC().Format(time.RFC3339)},
+		Bytes:   der,
+	}
+	return os.WriteFile(filepath.Join(s.dir, key.ID+".pem"), pem.EncodeToMemory(block), 0o600)
+}
+
+func (s *fileKeyStore) Delete(kid string) error {
+	err := os.Remove(filepath.Join(s.dir, kid+".pem"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// KeyManager signs with the newest key and keeps retired keys around for verification
+// until every token they signed has expired.
+type KeyManager struct {
+	store     KeyStore
+	algorithm string
+	// rotationInterval is how long a key is used for signing before a new one is generated
+	rotationInterval time.Duration
+	// retention is how long a key is still accepted after it stopped signing
+	retention time.Duration
+
+	mu   sync.RWMutex
+	keys []SigningKey // sorted newest first
+
+	reloadMu   sync.Mutex
+	lastReload time.Time
+}
+
+func NewKeyManager(store KeyStore, algorithm string, rotationInterval, retention time.Duration) (*KeyManager, error) {
+	if algorithm != SigningAlgRS256 && algorithm != SigningAlgEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	m := &KeyManager{
+		store:            store,
+		algorithm:        algorithm,
+		rotationInterval: rotationInterval,
+		retention:        retention,
+	}
+	if err := m.Rotate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Rotate reloads the key set, generates a new signing key when the current one is due
+// and deletes keys that are past their retention.
+func (m *KeyManager) Rotate() error {
+	keys, err := m.store.Load()
+	if err != nil {
+		return err
+	}
+	sortKeys(keys)
+
+	now := time.Now()
+	if len(keys) == 0 || keys[0].Algorithm != m.algorithm || now.Sub(keys[0].CreatedAt) >= m.rotationInterval {
+		key, err := m.generate(now)
+		if err != nil {
+			return err
+		}
+		if err := m.store.Save(key); err != nil {
+			return err
+		}
+		keys = append([]SigningKey{key}, keys...)
+	}
+
+	active, expired := m.splitRetained(keys, now)
+	for _, key := range expired {
+		if err := m.store.Delete(key.ID); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.keys = active
+	m.mu.Unlock()
+	return nil
+}
+
+// splitRetained splits keys sorted newest first into those still accepted and those past
+// their retention. A key stops signing when its successor is created, it is dropped once
+// the retention has passed.
+func (m *KeyManager) splitRetained(keys []SigningKey, now time.Time) (active, expired []SigningKey) {
+	active = []SigningKey{keys[0]}
+	for i := 1; i < len(keys); i++ {
+		if now.Sub(keys[i-1].CreatedAt) < m.retention {
+			active = append(active, keys[i])
+		} else {
+			expired = append(expired, keys[i])
+		}
+	}
+	return active, expired
+}
+
+// reload picks up keys that another replica added to the store since the last rotation,
+// at most once per keyReloadInterval. It reports whether the key set was reloaded.
+func (m *KeyManager) reload() bool {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if time.Since(m.lastReload) < keyReloadInterval {
+		return false
+	}
+	m.lastReload = time.Now()
+
+	keys, err := m.store.Load()
+	if err != nil {
+		log.Printf("failed to reload signing keys: %v", err)
+		return false
+	}
+	if len(keys) == 0 {
+		return false
+	}
+	sortKeys(keys)
+	active, _ := m.splitRetained(keys, time.Now())
+
+	m.mu.Lock()
+	m.keys = active
+	m.mu.Unlock()
+	return true
+}
+
+func sortKeys(keys []SigningKey) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+}
+
+// Start reloads and rotates the keys on every tick until ctx is done
+func (m *KeyManager) Start(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Rotate(); err != nil {
+					log.Printf("failed to rotate signing keys: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Current returns the key new tokens are signed with
+func (m *KeyManager) Current() SigningKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.keys[0]
+}
+
+// Find returns a key that is still accepted for verification. An unknown kid may be a key
+// another replica rotated in since the last tick, so the key set is reloaded once first.
+func (m *KeyManager) Find(kid string) (SigningKey, bool) {
+	if key, ok := m.find(kid); ok {
+		return key, true
+	}
+	if !m.reload() {
+		return SigningKey{}, false
+	}
+	return m.find(kid)
+}
+
+func (m *KeyManager) find(kid string) (SigningKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.keys {
+		if k.ID == kid {
+			return k, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// Keys returns every key that is still accepted for verification
+func (m *KeyManager) Keys() []SigningKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]SigningKey(nil), m.keys...)
+}
+
+func (m *KeyManager) generate(now time.Time) (SigningKey, error) {
+	suffix, err := GenerateRandomToken(6)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	key := SigningKey{
+		ID:        now.UTC().Format("20060102T150405") + "-" + suffix,
+		Algorithm: m.algorithm,
+		CreatedAt: now,
+	}
+
+	switch m.algorithm {
+	case SigningAlgRS256:
+		key.Private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case SigningAlgEdDSA:
+		_, key.Private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return key, nil
+}
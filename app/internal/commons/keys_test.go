@@ -0,0 +1,31 @@
+package commons
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyManager_FindReloadsUnknownKid(t *testing.T) {
+	store := NewFileKeyStore(t.TempDir())
+	replica, err := NewKeyManager(store, SigningAlgEdDSA, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	// Another replica whose key is due rotates a new one into the shared store
+	other, err := NewKeyManager(store, SigningAlgEdDSA, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	rotated := other.Current()
+
+	if _, ok := replica.Find(rotated.ID); !ok {
+		t.Errorf("KeyManager.Find() did not pick up the key rotated in by another replica")
+	}
+	// Misses right after a reload are not reloaded again
+	if replica.reload() {
+		t.Errorf("KeyManager.reload() reloaded again within %s", keyReloadInterval)
+	}
+	if _, ok := replica.Find("unknown"); ok {
+		t.Errorf("KeyManager.Find() found an unknown kid")
+	}
+}
@@ -0,0 +1,68 @@
+package commons
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	// Raw HTML is passed through and left to the sanitizer, which decides what stays
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var markdownPolicy = newMarkdownPolicy()
+
+// newMarkdownPolicy allows the HTML users may write, plus what the renderer adds: heading
+// IDs, the language class of code blocks and the checkboxes of task lists. Links get
+// rel="nofollow", scripts, styles and event handlers are dropped.
+func newMarkdownPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w#+.-]+$`)).OnElements("code")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// RenderMarkdown renders CommonMark with the GitHub extensions (tables, strikethrough,
+// autolinks and task lists) to sanitized HTML. Headings get an ID from their text to link
+// to, e.g. "## Getting started" becomes <h2 id="getting-started">.
+func RenderMarkdown(source string) (string, error) {
+	var buf bytes.Buffer
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{seen: map[string]bool{}}))
+	if err := markdown.Convert([]byte(source), &buf, parser.WithContext(ctx)); err != nil {
+		return "", err
+	}
+	return markdownPolicy.Sanitize(buf.String()), nil
+}
+
+// headingIDs makes heading IDs the way post slugs are made, numbering repeated ones
+type headingIDs struct {
+	seen map[string]bool
+}
+
+func (h *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	base := SlugifyName(string(value))
+	if base == "" {
+		base = "heading"
+	}
+	id := base
+	for i := 1; h.seen[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	h.seen[id] = true
+	return []byte(id)
+}
+
+func (h *headingIDs) Put(value []byte) {
+	h.seen[string(value)] = true
+}
@@ -0,0 +1,71 @@
+package commons
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "commonmark",
+			source: "Some *emphasis* and **strong** text",
+			want:   []string{"<p>Some <em>emphasis</em> and <strong>strong</strong> text</p>"},
+		},
+		{
+			name:   "heading anchors",
+			source: "## Getting started\n\n## Getting started\n\n## Crème brûlée",
+			want:   []string{`<h2 id="getting-started">`, `<h2 id="getting-started-1">`, `<h2 id="creme-brulee">`},
+		},
+		{
+			name:   "code block language",
+			source: "```go\nfmt.Println(\"<hi>\")\n```",
+			want:   []string{`<pre><code class="language-go">fmt.Println(&#34;&lt;hi&gt;&#34;)`},
+		},
+		{
+			name:   "gfm",
+			source: "| a | b |\n|---|---|\n| 1 | 2 |\n\n~~gone~~\n\n- [x] done\n\nhttps://example.com",
+			want:   []string{"<table>", "<del>gone</del>", `<input checked="" disabled="" type="checkbox"`, `<a href="https://example.com" rel="nofollow">`},
+		},
+		{
+			name:    "script",
+			source:  "Hello <script>alert(1)</script>\n\n<img src=\"x.png\" onerror=\"alert(1)\">",
+			want:    []string{`<img src="x.png">`},
+			notWant: []string{"<script", "alert", "onerror"},
+		},
+		{
+			name:    "javascript link",
+			source:  "[click](javascript:alert(1)) and <a href=\"javascript:alert(1)\">here</a>",
+			notWant: []string{"javascript:"},
+		},
+		{
+			name:    "class injection",
+			source:  "<code class=\"evil\">x</code> <p style=\"position:fixed\">y</p>",
+			notWant: []string{"evil", "style"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderMarkdown(tt.source)
+			if err != nil {
+				t.Fatalf("RenderMarkdown() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("RenderMarkdown() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("RenderMarkdown() = %q, must not contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
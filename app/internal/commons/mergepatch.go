@@ -0,0 +1,46 @@
+package commons
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to a JSON document. Members of the
+// patch replace those of the document, objects are merged recursively and null removes
+// a member.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := unmarshalNumbers(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := unmarshalNumbers(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
+
+// unmarshalNumbers keeps numbers as written, so large IDs survive the round trip
+func unmarshalNumbers(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,44 @@
+package commons
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher_Verify(t *testing.T) {
+	params := Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	hasher := NewPasswordHasher(params)
+
+	current, _ := hasher.Hash("password")
+	outdated, _ := NewPasswordHasher(Argon2Params{Memory: 32, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}).Hash("password")
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+
+	tests := []struct {
+		name            string
+		password        string
+		encoded         string
+		wantOK          bool
+		wantNeedsRehash bool
+		wantErr         error
+	}{
+		{name: "current argon2id hash", password: "password", encoded: current, wantOK: true},
+		{name: "wrong password", password: "wrongpassword", encoded: current},
+		{name: "argon2id hash with other cost", password: "password", encoded: outdated, wantOK: true, wantNeedsRehash: true},
+		{name: "bcrypt hash", password: "password", encoded: string(legacy), wantOK: true, wantNeedsRehash: true},
+		{name: "bcrypt hash with wrong password", password: "wrongpassword", encoded: string(legacy)},
+		{name: "unknown format", password: "password", encoded: "password", wantErr: ErrUnknownPasswordHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, needsRehash, err := hasher.Verify(tt.password, tt.encoded)
+			if err != tt.wantErr {
+				t.Fatalf("PasswordHasher.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || needsRehash != tt.wantNeedsRehash {
+				t.Errorf("PasswordHasher.Verify() = %v, %v, want %v, %v", ok, needsRehash, tt.wantOK, tt.wantNeedsRehash)
+			}
+		})
+	}
+}
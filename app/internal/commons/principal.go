@@ -0,0 +1,58 @@
+package commons
+
+import (
+	"app/internal/entities"
+	"context"
+	"strings"
+)
+
+// Principal is the authenticated caller, taken from a verified access token or API key
+type Principal struct {
+	UserID        uint
+	Name          string
+	Email         string
+	Role          string
+	EmailVerified bool
+	SessionID     uint
+	// APIKeyID is set when the caller authenticated with an API key instead of a session
+	APIKeyID uint
+	// Scopes is the space separated list of scopes granted to the API key
+	Scopes string
+}
+
+// HasScope reports whether the caller may act within the scope. Session tokens are not
+// scoped, API keys only carry the scopes chosen when they were created.
+func (p Principal) HasScope(scope string) bool {
+	if p.APIKeyID == 0 {
+		return true
+	}
+	for _, s := range strings.Fields(p.Scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// User returns the public fields of the caller, enough to embed as the author of a new resource
+func (p Principal) User() entities.User {
+	return entities.User{
+		ID:            p.UserID,
+		Name:          p.Name,
+		Email:         p.Email,
+		Role:          p.Role,
+		EmailVerified: p.EmailVerified,
+	}
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller put into the context by JWTMiddleware or APIKeyMiddleware
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
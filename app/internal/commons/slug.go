@@ -0,0 +1,34 @@
+package commons
+
+import (
+	"strings"
+
+	"github.com/gosimple/slug"
+)
+
+// maxSlugLength leaves room for a collision suffix within the slug column
+const maxSlugLength = 80
+
+// Slugify turns a title into a lowercase ASCII slug. Letters of other scripts are
+// transliterated, e.g. "Crème brûlée à Moscou" becomes "creme-brulee-a-moscou".
+func Slugify(title string) string {
+	if s := SlugifyName(title); s != "" {
+		return s
+	}
+	return "post"
+}
+
+// SlugifyName is Slugify for tag and category names, it returns an empty string when the
+// name has no letters or digits instead of falling back to a placeholder
+func SlugifyName(name string) string {
+	s := slug.Make(name)
+	if len(s) > maxSlugLength {
+		// Cut at the last word boundary that fits
+		if cut := strings.LastIndex(s[:maxSlugLength+1], "-"); cut > 0 {
+			s = s[:cut]
+		} else {
+			s = s[:maxSlugLength]
+		}
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package commons
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{name: "ascii", title: "Hello, World!", want: "hello-world"},
+		{name: "accents", title: "Crème brûlée à Moscou", want: "creme-brulee-a-moscou"},
+		{name: "german", title: "Straße über Köln", want: "strasse-uber-koln"},
+		{name: "cyrillic", title: "Привет мир", want: "privet-mir"},
+		{name: "extra separators", title: "  Go -- 1.21   release  ", want: "go-1-21-release"},
+		{name: "nothing left", title: "!!!", want: "post"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugify_LongTitle(t *testing.T) {
+	got := Slugify(strings.Repeat("word ", 40))
+	if len(got) > maxSlugLength || strings.HasSuffix(got, "-") || !strings.HasSuffix(got, "word") {
+		t.Errorf("Slugify() = %q, want whole words within %d characters", got, maxSlugLength)
+	}
+}
+
+func TestSlugifyName(t *testing.T) {
+	if got := SlugifyName("Backend Dev"); got != "backend-dev" {
+		t.Errorf("SlugifyName() = %q, want %q", got, "backend-dev")
+	}
+	if got := SlugifyName("!!!"); got != "" {
+		t.Errorf("SlugifyName() = %q, want an empty slug", got)
+	}
+}
@@ -0,0 +1,72 @@
+package commons
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors of RFC 6238 appendix B for SHA1, truncated to six digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode() at %d = %v, want %v", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	now := time.Now()
+	step := TOTPStep(now)
+
+	tests := []struct {
+		name   string
+		step   int64
+		wantOK bool
+	}{
+		{name: "current step", step: step, wantOK: true},
+		{name: "previous step", step: step - 1, wantOK: true},
+		{name: "next step", step: step + 1, wantOK: true},
+		{name: "too old", step: step - 2, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _ := TOTPCode(secret, tt.step)
+			got, ok := ValidateTOTP(secret, code, now)
+			if ok != tt.wantOK {
+				t.Fatalf("ValidateTOTP() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.step {
+				t.Errorf("ValidateTOTP() step = %d, want %d", got, tt.step)
+			}
+		})
+	}
+
+	uri := TOTPURI("Blog", "john@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Blog:john@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("TOTPURI() = %v", uri)
+	}
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// DeletedUserName is shown as the author of posts and comments kept from deleted accounts
+const DeletedUserName = "deleted user"
+
+// AccountExport is the personal data of a user handed out by the self-service export
+type AccountExport struct {
+	Profile    User      `json:"profile"`
+	Posts      []Post    `json:"posts"`
+	Comments   []Comment `json:"comments"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// DeleteAccountRequest confirms the deletion with the current password. Accounts created
+// through single sign-on have no password and send an empty body.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	IP       string `json:"-"`
+}
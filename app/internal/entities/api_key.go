@@ -0,0 +1,30 @@
+package entities
+
+import "time"
+
+type APIKey struct {
+	ID     uint   `json:"id"`
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	// Prefix is the start of the key, enough to tell keys apart without revealing them
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     string     `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=posts:write comments:write"`
+	// ExpiresInDays is optional, keys without it stay valid until revoked
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+// CreatedAPIKey is only returned once, the plain key can not be recovered afterwards
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -3,12 +3,13 @@ package entities
 import "time"
 
 type Comment struct {
-	ID        uint      `json:"id"`
-	PostID    uint      `json:"post_id"`
-	AuthorID  uint      `json:"author_id"`
-	Author    User      `json:"author,omitempty"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uint      `json:"id"`
+	PostID      uint      `json:"post_id"`
+	AuthorID    uint      `json:"author_id"`
+	Author      User      `json:"author,omitempty"`
+	Content     string    `json:"content"`
+	ContentHTML string    `json:"content_html"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type CreateCommentRequest struct {
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// LoginAttempt is one login try, kept for auditing
+type LoginAttempt struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	IP        string    `json:"ip"`
+	UserID    *uint     `json:"user_id,omitempty"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginCounter tracks consecutive failed logins for a key such as an email or an IP
+type LoginCounter struct {
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// Media is a file uploaded by a user, to be used as the cover of a post or inline in its
+// content
+type Media struct {
+	ID      uint `json:"id"`
+	OwnerID uint `json:"owner_id"`
+	// Key is where the file is kept in the storage
+	Key         string    `json:"-"`
+	URL         string    `json:"url"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type UploadMediaRequest struct {
+	Filename string
+	Data     []byte
+}
@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// UserIdentity links a local user to an account at an external identity provider
+type UserIdentity struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OIDCStart is where the browser is sent to sign in, FlowToken is kept in a cookie until
+// the provider redirects back
+type OIDCStart struct {
+	AuthURL   string
+	FlowToken string
+}
+
+type OIDCCallbackRequest struct {
+	Code      string `validate:"required"`
+	State     string `validate:"required"`
+	FlowToken string `validate:"required"`
+}
@@ -2,26 +2,90 @@ package entities
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// A post starts as a draft that only its author can see. Scheduled posts are published
+// automatically at their publish_at time. Published posts are listed publicly, archived
+// posts are no longer listed but stay readable at their URL.
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	PostStatusArchived  = "archived"
 )
 
 type Post struct {
-	ID        uint      `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	AuthorID  uint      `json:"author_id"`
-	Author    User      `json:"author,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint       `json:"id"`
+	Slug        string     `json:"slug"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	ContentHTML string     `json:"content_html"`
+	AuthorID    uint       `json:"author_id"`
+	Author      User       `json:"author,omitempty"`
+	CategoryID  *uint      `json:"category_id,omitempty"`
+	Category    *Category  `json:"category,omitempty"`
+	CoverID     *uint      `json:"cover_id,omitempty"`
+	Cover       *Media     `json:"cover,omitempty"`
+	Tags        []Tag      `json:"tags" gorm:"many2many:post_tags"`
+	Status      string     `json:"status"`
+	Version     uint       `json:"version"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// DeletedAt is set while the post is in the trash
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
 }
 
 type CreatePostRequest struct {
 	Title    string `json:"title" validate:"required"`
 	Content  string `json:"content" validate:"required"`
 	AuthorID uint   `json:"author_id" validate:"required"`
+	// Status defaults to published, send draft to keep the post private
+	Status string `json:"status" validate:"omitempty,oneof=draft published"`
+	// PublishAt schedules the post to be published automatically
+	PublishAt *time.Time `json:"publish_at" validate:"excluded_if=Status published"`
+	Tags      []string   `json:"tags" validate:"max=10,dive,required,max=50"`
+	// Category is the slug of the category
+	Category string `json:"category"`
+	// CoverID is the ID of an uploaded image to show as the cover of the post
+	CoverID *uint `json:"cover_id"`
+}
+
+// RenderPreviewRequest is Markdown to preview as it would be rendered for a post
+type RenderPreviewRequest struct {
+	Content string `json:"content" validate:"required"`
+}
+
+type RenderPreview struct {
+	ContentHTML string `json:"content_html"`
+}
+
+// PostSlug is a slug a post had before its title changed, it redirects to the current one
+type PostSlug struct {
+	ID        uint      `json:"id"`
+	PostID    uint      `json:"post_id"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SchedulePostRequest struct {
+	ID        uint      `json:"id" validate:"required"`
+	PublishAt time.Time `json:"publish_at" validate:"required"`
 }
 
 type UpdatePostRequest struct {
 	ID      uint   `json:"id" validate:"required"`
 	Title   string `json:"title" validate:"required"`
 	Content string `json:"content" validate:"required"`
+	// Tags replaces the tags of the post, nil keeps them
+	Tags *[]string `json:"tags" validate:"omitempty,max=10,dive,required,max=50"`
+	// Category is the slug of the new category, nil keeps it and an empty string removes it
+	Category *string `json:"category"`
+	// CoverID is the ID of the new cover image, nil keeps it and 0 removes it
+	CoverID *uint `json:"cover_id"`
+	// Version is the version of the post the update was made against, from If-Match
+	Version uint `json:"-"`
 }
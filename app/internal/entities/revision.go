@@ -0,0 +1,37 @@
+package entities
+
+import "time"
+
+// PostRevision is a version of the title and content of a post. Revisions are numbered
+// per post from 1, the one with the highest number is the current version.
+type PostRevision struct {
+	ID       uint   `json:"-"`
+	PostID   uint   `json:"post_id"`
+	Number   uint   `json:"number"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	EditorID uint   `json:"editor_id"`
+	// RestoredFrom is the number of the revision this one restored
+	RestoredFrom *uint     `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// RevisionDiff compares two revisions line by line, deletions are from From and insertions from To
+type RevisionDiff struct {
+	PostID  uint       `json:"post_id"`
+	From    uint       `json:"from"`
+	To      uint       `json:"to"`
+	Title   []DiffLine `json:"title"`
+	Content []DiffLine `json:"content"`
+}
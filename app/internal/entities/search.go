@@ -0,0 +1,33 @@
+package entities
+
+import "time"
+
+const (
+	SearchResultPost    = "post"
+	SearchResultComment = "comment"
+)
+
+type SearchQuery struct {
+	Text string `json:"q" validate:"required,max=200"`
+	// AuthorID only keeps posts and comments of this user
+	AuthorID uint `json:"author_id"`
+	// Tag only keeps posts with this tag slug, and the comments on them
+	Tag string `json:"tag"`
+	// From and To bound the publish date of posts and the creation date of comments, To is exclusive
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
+
+type SearchResult struct {
+	Type   string `json:"type"`
+	ID     uint   `json:"id"`
+	PostID uint   `json:"post_id"`
+	// PostSlug and Title are those of the post, also for comments
+	PostSlug string `json:"post_slug"`
+	Title    string `json:"title"`
+	// Snippet is an HTML escaped excerpt with the matching words wrapped in <mark>
+	Snippet  string    `json:"snippet"`
+	AuthorID uint      `json:"author_id"`
+	Date     time.Time `json:"date"`
+	Score    float64   `json:"score"`
+}
@@ -0,0 +1,33 @@
+package entities
+
+import "time"
+
+type Session struct {
+	ID               uint       `json:"id"`
+	UserID           uint       `json:"user_id"`
+	RefreshTokenHash string     `json:"-"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// LoginResponse carries the token pair, or a challenge when the user has to provide
+// a second factor before the tokens are issued
+type LoginResponse struct {
+	*TokenResponse
+	TwoFactorRequired  bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token,omitempty"`
+	ChallengeExpiresIn int64  `json:"challenge_expires_in,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
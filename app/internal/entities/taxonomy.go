@@ -0,0 +1,49 @@
+package entities
+
+import "time"
+
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// PostTag links a post to one of its tags
+type PostTag struct {
+	PostID uint `json:"post_id"`
+	TagID  uint `json:"tag_id"`
+}
+
+// TagCount is a tag with the number of published posts using it
+type TagCount struct {
+	Tag
+	PostCount int64 `json:"post_count"`
+}
+
+// Category is a node of the category tree, a post belongs to at most one category
+type Category struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	ParentID  *uint     `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CategoryNode is a category with its subcategories
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
+
+type CreateCategoryRequest struct {
+	Name     string `json:"name" validate:"required,max=50"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// PostFilter narrows the public post listing
+type PostFilter struct {
+	// TagSlug only keeps posts with this tag
+	TagSlug string
+	// CategoryIDs only keeps posts in one of these categories
+	CategoryIDs []uint
+}
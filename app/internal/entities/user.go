@@ -3,17 +3,26 @@ package entities
 import "time"
 
 type User struct {
-	ID           uint      `json:"id"`
-	Name         string    `json:"name"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID               uint       `json:"id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	PasswordHash     string     `json:"-"`
+	Role             string     `json:"role"`
+	Bio              string     `json:"bio"`
+	AvatarURL        string     `json:"avatar_url"`
+	EmailVerified    bool       `json:"email_verified"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty"`
+	TOTPSecret       string     `json:"-"`
+	TOTPLastUsedStep int64      `json:"-"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
 type UserLoginRequest struct {
 	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required,min=8"`
+	IP       string `json:"-"`
 }
 
 type UserRegisterRequest struct {
@@ -25,4 +34,29 @@ type UserRegisterRequest struct {
 type UserUpdatePasswordRequest struct {
 	Password    string `json:"password" validate:"required,min=8"`
 	NewPassword string `json:"new_password" validate:"required,min=8"`
+	IP          string `json:"-"`
+}
+
+type UpdateRoleRequest struct {
+	UserID uint   `json:"user_id" validate:"required"`
+	Role   string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// UpdateProfileRequest only changes the fields that are present in the request body
+type UpdateProfileRequest struct {
+	Name *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Bio  *string `json:"bio" validate:"omitempty,max=1000"`
+	// AvatarURL must be an http or https URL, an empty string removes the avatar
+	AvatarURL *string `json:"avatar_url" validate:"omitnil,max=500,eq=|http_url"`
+}
+
+// UserProfile is the public view of a user, it never exposes the email address
+type UserProfile struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Bio         string    `json:"bio"`
+	AvatarURL   string    `json:"avatar_url"`
+	PostCount   int64     `json:"post_count"`
+	LatestPosts []Post    `json:"latest_posts"`
+	CreatedAt   time.Time `json:"created_at"`
 }
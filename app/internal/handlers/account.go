@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+type AccountHandler struct {
+	usecases usecases.AccountUsecase
+}
+
+func NewAccountHandler(uc usecases.AccountUsecase) *AccountHandler {
+	return &AccountHandler{usecases: uc}
+}
+
+func (h *AccountHandler) Export(w http.ResponseWriter, r *http.Request) {
+	export, err := h.usecases.Export(r.Context())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	filename := fmt.Sprintf("account-export-%d-%s.zip", export.Profile.ID, export.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	// The status is already sent at this point, so a failure can only be logged
+	if err := usecases.WriteExportArchive(w, export); err != nil {
+		log.Printf("failed to write account export of user %d: %v", export.Profile.ID, err)
+	}
+}
+
+func (h *AccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	// The body is optional for accounts without a password
+	var req entities.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	req.IP = commons.ClientIP(r)
+
+	if err := h.usecases.Delete(r.Context(), &req); err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrInvalidCredentials || err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrTooManyAttempts || err == commons.ErrAccountLocked {
+			status = http.StatusTooManyRequests
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, "Account deleted successfully")
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	usecases usecases.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(uc usecases.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{usecases: uc}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req entities.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	key, err := h.usecases.Create(r.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.usecases.List(r.Context())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.usecases.Revoke(r.Context(), uint(id)); err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, "API key revoked successfully")
+}
@@ -41,7 +41,7 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	res, err := h.usecases.CreateComment(r.Context(), &comment)
 	if err != nil {
 		status := http.StatusInternalServerError
-		if err == commons.ErrForbidden {
+		if err == commons.ErrForbidden || err == commons.ErrEmailNotVerified {
 			status = http.StatusForbidden
 		} else if err == commons.ErrBadRequest {
 			status = http.StatusBadRequest
@@ -89,3 +89,50 @@ func (h *CommentHandler) GetCommentsByPostID(w http.ResponseWriter, r *http.Requ
 
 	commons.SuccessResponse(w, http.StatusOK, comments)
 }
+
+// CommentAuthor returns the author of the comment in the URL, for RequireOwnerOrPermission
+func (h *CommentHandler) CommentAuthor(r *http.Request) (uint, error) {
+	vars := mux.Vars(r)
+	postID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		return 0, commons.ErrBadRequest
+	}
+	commentID, err := strconv.ParseUint(vars["commentId"], 10, 32)
+	if err != nil {
+		return 0, commons.ErrBadRequest
+	}
+	return h.usecases.GetCommentAuthorID(r.Context(), uint(postID), uint(commentID))
+}
+
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	// retrieve postId and commentId from URL
+	vars := mux.Vars(r)
+
+	postID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	commentID, err := strconv.ParseUint(vars["commentId"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.usecases.DeleteComment(r.Context(), uint(postID), uint(commentID))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrForbidden {
+			status = http.StatusForbidden
+		} else if err == commons.ErrBadRequest {
+			status = http.StatusBadRequest
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusOK, "Comment deleted successfully")
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// multipartOverhead is room for the headers and boundaries around the uploaded file
+const multipartOverhead = 64 << 10
+
+type MediaHandler struct {
+	usecases usecases.MediaUsecase
+	maxSize  int64
+}
+
+func NewMediaHandler(uc usecases.MediaUsecase, maxSize int64) *MediaHandler {
+	return &MediaHandler{usecases: uc, maxSize: maxSize}
+}
+
+// Upload takes a multipart/form-data request with the image in the "file" field
+func (h *MediaHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartOverhead)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			commons.ErrorResponse(w, http.StatusRequestEntityTooLarge, commons.ErrMediaTooLarge)
+			return
+		}
+		commons.ErrorResponse(w, http.StatusBadRequest, commons.ErrBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, commons.ErrBadRequest)
+		return
+	}
+
+	res, err := h.usecases.Upload(r.Context(), &entities.UploadMediaRequest{Filename: header.Filename, Data: data})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrBadRequest {
+			status = http.StatusBadRequest
+		} else if err == commons.ErrMediaTooLarge || err == commons.ErrMediaQuotaExceeded {
+			status = http.StatusRequestEntityTooLarge
+		} else if err == commons.ErrUnsupportedMediaType {
+			status = http.StatusUnsupportedMediaType
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusCreated, res)
+}
+
+func (h *MediaHandler) GetMyMedia(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	media, err := h.usecases.GetMyMedia(r.Context(), limit, page)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusOK, media)
+}
+
+// MediaOwner returns who uploaded the file in the URL, for RequireOwnerOrPermission
+func (h *MediaHandler) MediaOwner(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, commons.ErrBadRequest
+	}
+	return h.usecases.GetMediaOwnerID(r.Context(), uint(id))
+}
+
+func (h *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.usecases.DeleteMedia(r.Context(), uint(id))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrForbidden {
+			status = http.StatusForbidden
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		} else if err == commons.ErrMediaInUse {
+			status = http.StatusConflict
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusOK, "Media deleted")
+}
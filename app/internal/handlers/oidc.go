@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// oidcFlowCookie keeps the signed state of a sign-in between the redirect to the provider
+// and the callback. It is limited to the callback path and never readable by scripts.
+const oidcFlowCookie = "oidc_flow"
+
+type OIDCHandler struct {
+	usecases usecases.OIDCUsecase
+}
+
+func NewOIDCHandler(uc usecases.OIDCUsecase) *OIDCHandler {
+	return &OIDCHandler{usecases: uc}
+}
+
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	start, err := h.usecases.Start(r.Context())
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    start.FlowToken,
+		Path:     "/auth/oidc",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		// Lax so the cookie is sent on the top-level redirect back from the provider
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, start.AuthURL, http.StatusFound)
+}
+
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	// The flow is single use, whatever the outcome
+	http.SetCookie(w, &http.Cookie{Name: oidcFlowCookie, Path: "/auth/oidc", MaxAge: -1, HttpOnly: true})
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		commons.ErrorResponse(w, http.StatusUnauthorized, errors.New(providerErr+": "+query.Get("error_description")))
+		return
+	}
+
+	req := entities.OIDCCallbackRequest{
+		Code:  query.Get("code"),
+		State: query.Get("state"),
+	}
+	if cookie, err := r.Cookie(oidcFlowCookie); err == nil {
+		req.FlowToken = cookie.Value
+	}
+
+	res, err := h.usecases.Callback(r.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrInvalidOIDCState || err == commons.ErrOIDCLoginFailed {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrOIDCAccountConflict {
+			status = http.StatusConflict
+		} else if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, res)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type PasswordHandler struct {
+	usecases usecases.PasswordUsecase
+}
+
+func NewPasswordHandler(uc usecases.PasswordUsecase) *PasswordHandler {
+	return &PasswordHandler{usecases: uc}
+}
+
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req entities.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.usecases.ForgotPassword(r.Context(), &req); err != nil {
+		status := http.StatusInternalServerError
+		if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusAccepted, "If the email is registered, a reset link has been sent")
+}
+
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req entities.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.usecases.ResetPassword(r.Context(), &req); err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrInvalidResetToken {
+			status = http.StatusBadRequest
+		} else if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, "Password has been reset successfully")
+}
@@ -4,13 +4,21 @@ import (
 	"app/internal/commons"
 	"app/internal/entities"
 	usecases "app/internal/usecases"
+	"context"
 	"encoding/json"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 )
 
+// mergePatchMediaType is the content type of JSON Merge Patch (RFC 7396)
+const mergePatchMediaType = "application/merge-patch+json"
+
 type PostHandler struct {
 	usecases usecases.PostUsecase
 }
@@ -28,18 +36,47 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 
 	res, err := h.usecases.CreatePost(r.Context(), &post)
 	if err != nil {
-		commons.ErrorResponse(w, http.StatusInternalServerError, err)
+		status := http.StatusInternalServerError
+		if err == commons.ErrEmailNotVerified {
+			status = http.StatusForbidden
+		} else if _, ok := err.(validator.ValidationErrors); ok || err == commons.ErrInvalidPublishTime || err == commons.ErrInvalidTagName || err == commons.ErrUnknownCategory || err == commons.ErrInvalidCover {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
 		return
 	}
 
+	w.Header().Set("ETag", commons.ETag(res.Version))
 	commons.SuccessResponse(w, http.StatusCreated, res)
 }
 
+// PostAuthor returns the author of the post in the URL, for RequireOwnerOrPermission
+func (h *PostHandler) PostAuthor(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, commons.ErrBadRequest
+	}
+	return h.usecases.GetPostAuthorID(r.Context(), uint(id))
+}
+
+// TrashedPostAuthor returns the author of the post in the trash in the URL, for
+// RequireOwnerOrPermission
+func (h *PostHandler) TrashedPostAuthor(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, commons.ErrBadRequest
+	}
+	return h.usecases.GetTrashedPostAuthorID(r.Context(), uint(id))
+}
+
 func (h *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 
-	posts, err := h.usecases.GetAllPosts(r.Context(), limit, page)
+	tag := r.URL.Query().Get("tag")
+	category := r.URL.Query().Get("category")
+
+	posts, err := h.usecases.GetAllPosts(r.Context(), tag, category, limit, page)
 	if err != nil {
 		commons.ErrorResponse(w, http.StatusInternalServerError, err)
 		return
@@ -75,6 +112,30 @@ func (h *PostHandler) GetPostByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", commons.ETag(post.Version))
+	commons.SuccessResponse(w, http.StatusOK, post)
+}
+
+func (h *PostHandler) GetPostBySlug(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	post, moved, err := h.usecases.GetPostBySlug(r.Context(), vars["slug"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrBadRequest {
+			status = http.StatusBadRequest
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	if moved {
+		http.Redirect(w, r, "/posts/by-slug/"+url.PathEscape(post.Slug), http.StatusMovedPermanently)
+		return
+	}
+
+	w.Header().Set("ETag", commons.ETag(post.Version))
 	commons.SuccessResponse(w, http.StatusOK, post)
 }
 
@@ -97,25 +158,87 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	post.ID = uint(id)
+	if post.Version, err = ifMatchVersion(r); err != nil {
+		commons.ErrorResponse(w, http.StatusPreconditionFailed, err)
+		return
+	}
 
 	res, err := h.usecases.UpdatePost(r.Context(), &post)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == commons.ErrForbidden {
-			status = http.StatusForbidden
-		} else if err == commons.ErrBadRequest {
-			status = http.StatusBadRequest
-		} else if err == commons.ErrNotFound {
-			status = http.StatusNotFound
-		}
+		commons.ErrorResponse(w, updateErrorStatus(err), err)
+		return
+	}
 
-		commons.ErrorResponse(w, status, err)
+	w.Header().Set("ETag", commons.ETag(res.Version))
+	commons.SuccessResponse(w, http.StatusOK, res)
+}
+
+// PatchPost applies a JSON Merge Patch, sent as application/merge-patch+json, to a post
+func (h *PostHandler) PatchPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
 		return
 	}
 
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != mergePatchMediaType {
+		w.Header().Set("Accept-Patch", mergePatchMediaType)
+		commons.ErrorResponse(w, http.StatusUnsupportedMediaType, commons.ErrUnsupportedPatch)
+		return
+	}
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusPreconditionFailed, err)
+		return
+	}
+
+	res, err := h.usecases.PatchPost(r.Context(), uint(id), version, patch)
+	if err != nil {
+		commons.ErrorResponse(w, updateErrorStatus(err), err)
+		return
+	}
+
+	w.Header().Set("ETag", commons.ETag(res.Version))
 	commons.SuccessResponse(w, http.StatusOK, res)
 }
 
+// ifMatchVersion returns the version in the If-Match header, or 0 when there is none
+func ifMatchVersion(r *http.Request) (uint, error) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return 0, nil
+	}
+	version, ok := commons.ParseETag(ifMatch)
+	if !ok {
+		return 0, commons.ErrVersionMismatch
+	}
+	return version, nil
+}
+
+func updateErrorStatus(err error) int {
+	status := http.StatusInternalServerError
+	if err == commons.ErrUnauthorized {
+		status = http.StatusUnauthorized
+	} else if err == commons.ErrForbidden {
+		status = http.StatusForbidden
+	} else if _, ok := err.(validator.ValidationErrors); ok || err == commons.ErrBadRequest || err == commons.ErrInvalidTagName || err == commons.ErrUnknownCategory || err == commons.ErrInvalidCover {
+		status = http.StatusBadRequest
+	} else if err == commons.ErrNotFound {
+		status = http.StatusNotFound
+	} else if err == commons.ErrVersionRequired {
+		status = http.StatusPreconditionRequired
+	} else if err == commons.ErrVersionMismatch {
+		status = http.StatusPreconditionFailed
+	}
+	return status
+}
+
 func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	// retrieve id from URL and pass it to usecase
 	vars := mux.Vars(r)
@@ -143,5 +266,239 @@ func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	commons.SuccessResponse(w, http.StatusOK, "Post deleted successfully")
+	commons.SuccessResponse(w, http.StatusOK, "Post moved to the trash")
+}
+
+func (h *PostHandler) PublishPost(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.usecases.PublishPost)
+}
+
+func (h *PostHandler) UnpublishPost(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.usecases.UnpublishPost)
+}
+
+func (h *PostHandler) ArchivePost(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.usecases.ArchivePost)
+}
+
+func (h *PostHandler) SchedulePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req entities.SchedulePostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	req.ID = uint(id)
+
+	post, err := h.usecases.SchedulePost(r.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrForbidden {
+			status = http.StatusForbidden
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		} else if err == commons.ErrInvalidPostStatus || err == commons.ErrVersionMismatch {
+			status = http.StatusConflict
+		} else if _, ok := err.(validator.ValidationErrors); ok || err == commons.ErrInvalidPublishTime {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	w.Header().Set("ETag", commons.ETag(post.Version))
+	commons.SuccessResponse(w, http.StatusOK, post)
+}
+
+func (h *PostHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id uint) (*entities.Post, error)) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := change(r.Context(), uint(id))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrForbidden {
+			status = http.StatusForbidden
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		} else if err == commons.ErrInvalidPostStatus || err == commons.ErrVersionMismatch {
+			status = http.StatusConflict
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	w.Header().Set("ETag", commons.ETag(post.Version))
+	commons.SuccessResponse(w, http.StatusOK, post)
+}
+
+func (h *PostHandler) GetMyPosts(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	posts, err := h.usecases.GetMyPosts(r.Context(), r.URL.Query().Get("status"), limit, page)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrInvalidPostStatus {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusOK, posts)
+}
+
+func (h *PostHandler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.usecases.RestorePost(r.Context(), uint(id))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrForbidden {
+			status = http.StatusForbidden
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	w.Header().Set("ETag", commons.ETag(post.Version))
+	commons.SuccessResponse(w, http.StatusOK, post)
+}
+
+func (h *PostHandler) GetMyTrash(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	posts, err := h.usecases.GetMyTrash(r.Context(), limit, page)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusOK, posts)
+}
+
+func (h *PostHandler) RenderPreview(w http.ResponseWriter, r *http.Request) {
+	var req entities.RenderPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	preview, err := h.usecases.RenderPreview(r.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusOK, preview)
+}
+
+func (h *PostHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	revisions, err := h.usecases.GetRevisions(r.Context(), uint(id))
+	if err != nil {
+		commons.ErrorResponse(w, revisionErrorStatus(err), err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusOK, revisions)
+}
+
+func (h *PostHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	from, _ := strconv.ParseUint(r.URL.Query().Get("from"), 10, 32)
+	to, _ := strconv.ParseUint(r.URL.Query().Get("to"), 10, 32)
+
+	diff, err := h.usecases.DiffRevisions(r.Context(), uint(id), uint(from), uint(to))
+	if err != nil {
+		commons.ErrorResponse(w, revisionErrorStatus(err), err)
+		return
+	}
+
+	commons.SuccessResponse(w, http.StatusOK, diff)
+}
+
+func (h *PostHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	rev, err := strconv.ParseUint(vars["rev"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.usecases.RestoreRevision(r.Context(), uint(id), uint(rev))
+	if err != nil {
+		commons.ErrorResponse(w, revisionErrorStatus(err), err)
+		return
+	}
+
+	w.Header().Set("ETag", commons.ETag(post.Version))
+	commons.SuccessResponse(w, http.StatusOK, post)
+}
+
+func revisionErrorStatus(err error) int {
+	status := http.StatusInternalServerError
+	if err == commons.ErrUnauthorized {
+		status = http.StatusUnauthorized
+	} else if err == commons.ErrForbidden {
+		status = http.StatusForbidden
+	} else if err == commons.ErrNotFound {
+		status = http.StatusNotFound
+	} else if err == commons.ErrBadRequest {
+		status = http.StatusBadRequest
+	} else if err == commons.ErrVersionMismatch {
+		status = http.StatusConflict
+	}
+	return status
 }
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type ProfileHandler struct {
+	usecases usecases.ProfileUsecase
+}
+
+func NewProfileHandler(uc usecases.ProfileUsecase) *ProfileHandler {
+	return &ProfileHandler{usecases: uc}
+}
+
+func (h *ProfileHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	user, err := h.usecases.GetMe(r.Context())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, user)
+}
+
+func (h *ProfileHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var req entities.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.usecases.UpdateMe(r.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		} else if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, user)
+}
+
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	// retrieve id from URL and pass it to usecase
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	profile, err := h.usecases.GetProfile(r.Context(), uint(id))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrBadRequest {
+			status = http.StatusBadRequest
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, profile)
+}
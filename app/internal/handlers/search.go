@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type SearchHandler struct {
+	usecases usecases.SearchUsecase
+}
+
+func NewSearchHandler(uc usecases.SearchUsecase) *SearchHandler {
+	return &SearchHandler{usecases: uc}
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	limit, _ := strconv.Atoi(params.Get("limit"))
+	page, _ := strconv.Atoi(params.Get("page"))
+
+	query := entities.SearchQuery{Text: params.Get("q"), Tag: params.Get("tag")}
+	if author := params.Get("author"); author != "" {
+		id, err := strconv.ParseUint(author, 10, 32)
+		if err != nil {
+			commons.ErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		query.AuthorID = uint(id)
+	}
+	var err error
+	if query.From, err = parseSearchDate(params.Get("from"), false); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if query.To, err = parseSearchDate(params.Get("to"), true); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	results, err := h.usecases.Search(r.Context(), &query, limit, page)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, results)
+}
+
+// parseSearchDate accepts an RFC 3339 time or a YYYY-MM-DD date. As the end of a range, a
+// date includes that whole day.
+func parseSearchDate(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
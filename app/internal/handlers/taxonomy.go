@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type TaxonomyHandler struct {
+	usecases usecases.TaxonomyUsecase
+}
+
+func NewTaxonomyHandler(uc usecases.TaxonomyUsecase) *TaxonomyHandler {
+	return &TaxonomyHandler{usecases: uc}
+}
+
+func (h *TaxonomyHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.usecases.GetTags(r.Context())
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, tags)
+}
+
+func (h *TaxonomyHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.usecases.GetCategories(r.Context())
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, categories)
+}
+
+func (h *TaxonomyHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req entities.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	category, err := h.usecases.CreateCategory(r.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrForbidden {
+			status = http.StatusForbidden
+		} else if err == commons.ErrCategoryExists {
+			status = http.StatusConflict
+		} else if _, ok := err.(validator.ValidationErrors); ok || err == commons.ErrInvalidTagName || err == commons.ErrUnknownCategory {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusCreated, category)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	usecases "app/internal/usecases"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type TwoFactorHandler struct {
+	usecases usecases.TwoFactorUsecase
+}
+
+func NewTwoFactorHandler(uc usecases.TwoFactorUsecase) *TwoFactorHandler {
+	return &TwoFactorHandler{usecases: uc}
+}
+
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	enrollment, err := h.usecases.Enroll(r.Context())
+	if err != nil {
+		commons.ErrorResponse(w, twoFactorErrorStatus(err), err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, enrollment)
+}
+
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	var req entities.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	req.IP = commons.ClientIP(r)
+
+	codes, err := h.usecases.Confirm(r.Context(), &req)
+	if err != nil {
+		commons.ErrorResponse(w, twoFactorErrorStatus(err), err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, codes)
+}
+
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	var req entities.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	req.IP = commons.ClientIP(r)
+
+	if err := h.usecases.Disable(r.Context(), &req); err != nil {
+		commons.ErrorResponse(w, twoFactorErrorStatus(err), err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, "Two-factor authentication disabled")
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var req entities.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	req.IP = commons.ClientIP(r)
+
+	codes, err := h.usecases.RegenerateRecoveryCodes(r.Context(), &req)
+	if err != nil {
+		commons.ErrorResponse(w, twoFactorErrorStatus(err), err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, codes)
+}
+
+func twoFactorErrorStatus(err error) int {
+	status := http.StatusInternalServerError
+	if err == commons.ErrUnauthorized {
+		status = http.StatusUnauthorized
+	} else if err == commons.ErrNotFound {
+		status = http.StatusNotFound
+	} else if err == commons.ErrTwoFactorEnabled || err == commons.ErrTwoFactorNotEnabled || err == commons.ErrTwoFactorNotEnrolled {
+		status = http.StatusConflict
+	} else if err == commons.ErrInvalidTwoFactorCode {
+		status = http.StatusBadRequest
+	} else if err == commons.ErrTooManyAttempts || err == commons.ErrAccountLocked {
+		status = http.StatusTooManyRequests
+	} else if _, ok := err.(validator.ValidationErrors); ok {
+		status = http.StatusBadRequest
+	}
+	return status
+}
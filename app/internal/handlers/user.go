@@ -6,14 +6,19 @@ import (
 	usecases "app/internal/usecases"
 	"encoding/json"
 	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
 )
 
 type UserHandler struct {
-	usecases usecases.UserUsecase
+	usecases     usecases.UserUsecase
+	verification usecases.EmailVerificationUsecase
 }
 
-func NewUserHandler(u usecases.UserUsecase) *UserHandler {
-	return &UserHandler{usecases: u}
+func NewUserHandler(u usecases.UserUsecase, v usecases.EmailVerificationUsecase) *UserHandler {
+	return &UserHandler{usecases: u, verification: v}
 }
 
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
@@ -41,10 +46,178 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		commons.ErrorResponse(w, http.StatusBadRequest, err)
 		return
 	}
+	req.IP = commons.ClientIP(r)
+
 	token, err := h.usecases.Login(r.Context(), &req)
+	if err != nil {
+		status := http.StatusUnauthorized
+		if err == commons.ErrTooManyAttempts || err == commons.ErrAccountLocked {
+			status = http.StatusTooManyRequests
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, token)
+}
+
+func (h *UserHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req entities.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	req.IP = commons.ClientIP(r)
+
+	token, err := h.usecases.LoginTwoFactor(r.Context(), &req)
+	if err != nil {
+		status := http.StatusUnauthorized
+		if err == commons.ErrTooManyAttempts || err == commons.ErrAccountLocked {
+			status = http.StatusTooManyRequests
+		} else if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, token)
+}
+
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req entities.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	token, err := h.usecases.RefreshToken(r.Context(), &req)
 	if err != nil {
 		commons.ErrorResponse(w, http.StatusUnauthorized, err)
 		return
 	}
 	commons.SuccessResponse(w, http.StatusOK, token)
 }
+
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.usecases.Logout(r.Context()); err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, "Logged out successfully")
+}
+
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	if err := h.usecases.LogoutAll(r.Context()); err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, "Logged out from all sessions successfully")
+}
+
+func (h *UserHandler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
+	var req entities.UserUpdatePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	req.IP = commons.ClientIP(r)
+	token, err := h.usecases.UpdatePassword(r.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrInvalidCredentials || err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrTooManyAttempts || err == commons.ErrAccountLocked {
+			status = http.StatusTooManyRequests
+		} else if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, token)
+}
+
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if err := h.verification.VerifyEmail(r.Context(), token); err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrBadRequest || err == commons.ErrInvalidVerifyToken {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, "Email verified successfully")
+}
+
+func (h *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	if err := h.verification.ResendVerification(r.Context()); err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrUnauthorized {
+			status = http.StatusUnauthorized
+		} else if err == commons.ErrEmailAlreadyVerified {
+			status = http.StatusConflict
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusAccepted, "Verification email sent")
+}
+
+func (h *UserHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	// retrieve user id from URL
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req entities.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	req.UserID = uint(id)
+
+	user, err := h.usecases.UpdateRole(r.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		} else if _, ok := err.(validator.ValidationErrors); ok {
+			status = http.StatusBadRequest
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	// retrieve user id from URL
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		commons.ErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.usecases.Unlock(r.Context(), uint(id)); err != nil {
+		status := http.StatusInternalServerError
+		if err == commons.ErrNotFound {
+			status = http.StatusNotFound
+		}
+		commons.ErrorResponse(w, status, err)
+		return
+	}
+	commons.SuccessResponse(w, http.StatusOK, "User unlocked successfully")
+}
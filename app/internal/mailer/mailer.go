@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+//go:generate mockery --name=Mailer --output=mocks --outpkg=mocks
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures the Mailer built by NewMailer
+type Config struct {
+	Driver       string // "outbox" (default) or "smtp"
+	From         string
+	OutboxDir    string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+func NewMailer(c Config) (Mailer, error) {
+	switch c.Driver {
+	case "", "outbox":
+		return NewOutboxMailer(c.OutboxDir, c.From), nil
+	case "smtp":
+		return NewSMTPMailer(c.SMTPHost, c.SMTPPort, c.SMTPUsername, c.SMTPPassword, c.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", c.Driver)
+	}
+}
+
+// build renders the message as a plain text RFC 5322 email
+func (m Message) build(from string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + strings.Join(m.To, ", ") + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", m.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
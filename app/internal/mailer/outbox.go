@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"app/internal/commons"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// outboxMailer writes every message as an .eml file into a directory instead of
+// delivering it, which is enough for local development and tests
+type outboxMailer struct {
+	dir  string
+	from string
+}
+
+func NewOutboxMailer(dir, from string) Mailer {
+	if dir == "" {
+		dir = "outbox"
+	}
+	return &outboxMailer{dir: dir, from: from}
+}
+
+func (m *outboxMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	suffix, err := commons.GenerateRandomToken(6)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), suffix)
+
+	return os.WriteFile(filepath.Join(m.dir, name), msg.build(m.from), 0o644)
+}
@@ -0,0 +1,110 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Identity is the verified subject of an ID token
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+//go:generate mockery --name=Provider --output=mocks --outpkg=mocks
+type Provider interface {
+	// AuthCodeURL returns the authorization endpoint URL the user is redirected to
+	AuthCodeURL(state, nonce, verifier string) string
+	// Exchange redeems the authorization code and verifies the returned ID token
+	Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error)
+}
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type provider struct {
+	oauth    oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewProvider discovers the endpoints and signing keys of the issuer from its
+// /.well-known/openid-configuration document
+func NewProvider(ctx context.Context, c Config) (Provider, error) {
+	discovered, err := gooidc.NewProvider(ctx, c.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discover %s: %w", c.Issuer, err)
+	}
+
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+	}
+	return &provider{
+		oauth: oauth2.Config{
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURL:  c.RedirectURL,
+			Endpoint:     discovered.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: discovered.Verifier(&gooidc.Config{ClientID: c.ClientID}),
+	}, nil
+}
+
+func (p *provider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.oauth.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+func (p *provider) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Identity{}, fmt.Errorf("exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return Identity{}, errors.New("token response has no id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return Identity{}, fmt.Errorf("verify id_token: %w", err)
+	}
+	// The nonce ties the ID token to the login that was started in this browser
+	if idToken.Nonce != nonce {
+		return Identity{}, errors.New("id_token nonce mismatch")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return Identity{}, fmt.Errorf("decode id_token claims: %w", err)
+	}
+
+	return Identity{
+		Issuer:        idToken.Issuer,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// GenerateVerifier returns a new PKCE code verifier
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
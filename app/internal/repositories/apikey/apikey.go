@@ -0,0 +1,111 @@
+package apikey
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=APIKeyRepository --output=mocks --outpkg=mocks
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *entities.APIKey) error
+	FindByHash(ctx context.Context, hash string) (entities.APIKey, error)
+	ListByUserID(ctx context.Context, userID uint) ([]entities.APIKey, error)
+	Revoke(ctx context.Context, id, userID uint) error
+	TouchLastUsed(ctx context.Context, id uint, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewAPIKeyRepository(db *gorm.DB, timeout time.Duration) APIKeyRepository {
+	return &apiKeyRepository{db: db, ContextTimeout: timeout}
+}
+
+// CreateAPIKey inserts a new API key into the database
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key *entities.APIKey) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// FindByHash returns the API key with the given hash
+func (r *apiKeyRepository) FindByHash(ctx context.Context, hash string) (entities.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var key entities.APIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.APIKey{}, commons.ErrNotFound
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.APIKey{}, commons.ErrTimeout
+		}
+		return entities.APIKey{}, err
+	}
+	return key, nil
+}
+
+// ListByUserID returns every API key of the user, newest first
+func (r *apiKeyRepository) ListByUserID(ctx context.Context, userID uint) ([]entities.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	keys := []entities.APIKey{}
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke marks an active key of the user as revoked. Keys of other users are reported as
+// not found, so their IDs can not be probed.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	res := r.db.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return commons.ErrNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records when the key was last used
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).Model(&entities.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package apikey
+
+import (
+	"time"
+)
+
+type APIKey struct {
+	ID         uint   `gorm:"primary_key"`
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"type:varchar(100);not null"`
+	Prefix     string `gorm:"type:varchar(20);not null"`
+	KeyHash    string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Scopes     string `gorm:"type:varchar(255);not null"`
+	LastUsedAt *time.Time
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
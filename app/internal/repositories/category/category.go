@@ -0,0 +1,94 @@
+package category
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=CategoryRepository --output=mocks --outpkg=mocks
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category *entities.Category) error
+	GetAllCategories(ctx context.Context) ([]entities.Category, error)
+	GetCategoryByID(ctx context.Context, id uint) (entities.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (entities.Category, error)
+}
+
+type categoryRepository struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewCategoryRepository(db *gorm.DB, timeout time.Duration) CategoryRepository {
+	return &categoryRepository{db: db, ContextTimeout: timeout}
+}
+
+// CreateCategory inserts a new category into the database
+func (r *categoryRepository) CreateCategory(ctx context.Context, category *entities.Category) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).Create(category).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// GetAllCategories returns every category sorted by name, the tree is small enough to be
+// assembled in memory
+func (r *categoryRepository) GetAllCategories(ctx context.Context) ([]entities.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var categories []entities.Category
+	if err := r.db.WithContext(ctx).Order("name").Find(&categories).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetCategoryByID returns a category by its ID
+func (r *categoryRepository) GetCategoryByID(ctx context.Context, id uint) (entities.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var category entities.Category
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Category{}, commons.ErrNotFound
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.Category{}, commons.ErrTimeout
+		}
+		return entities.Category{}, err
+	}
+	return category, nil
+}
+
+// GetCategoryBySlug returns a category by its slug
+func (r *categoryRepository) GetCategoryBySlug(ctx context.Context, slug string) (entities.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var category entities.Category
+	if err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Category{}, commons.ErrNotFound
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.Category{}, commons.ErrTimeout
+		}
+		return entities.Category{}, err
+	}
+	return category, nil
+}
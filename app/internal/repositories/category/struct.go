@@ -0,0 +1,13 @@
+package category
+
+import (
+	"time"
+)
+
+type Category struct {
+	ID        uint      `gorm:"primary_key"`
+	Name      string    `gorm:"type:varchar(50);not null"`
+	Slug      string    `gorm:"type:varchar(100);not null;uniqueIndex"`
+	ParentID  *uint     `gorm:"index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
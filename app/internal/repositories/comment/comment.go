@@ -14,6 +14,12 @@ import (
 type CommentRepository interface {
 	CreateComment(ctx context.Context, comment *entities.Comment) error
 	GetCommentsByPostId(ctx context.Context, postId uint, limit, offset int) ([]entities.Comment, error)
+	GetCommentById(ctx context.Context, id uint) (*entities.Comment, error)
+	DeleteComment(ctx context.Context, id uint) error
+	GetAllCommentsByAuthor(ctx context.Context, authorID uint) ([]entities.Comment, error)
+	GetCommentsAfterID(ctx context.Context, afterID uint, limit int) ([]entities.Comment, error)
+	GetCommentsWithoutContentHTML(ctx context.Context, limit int) ([]entities.Comment, error)
+	SetContentHTML(ctx context.Context, id uint, html string) error
 }
 
 type commentRepo struct {
@@ -63,3 +69,101 @@ func (r *commentRepo) GetCommentsByPostId(ctx context.Context, postId uint, limi
 	}
 	return comments, nil
 }
+
+// GetCommentById returns a comment by its ID
+func (r *commentRepo) GetCommentById(ctx context.Context, id uint) (*entities.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var comment entities.Comment
+	err := r.db.WithContext(ctx).First(&comment, id).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commons.ErrNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// DeleteComment deletes a comment by its ID
+func (r *commentRepo) DeleteComment(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Delete(&Comment{}, id).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// GetAllCommentsByAuthor returns every comment of an author, oldest first
+func (r *commentRepo) GetAllCommentsByAuthor(ctx context.Context, authorID uint) ([]entities.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var comments []entities.Comment
+	err := r.db.WithContext(ctx).Where("author_id = ?", authorID).Order("created_at asc").Find(&comments).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return comments, nil
+}
+
+// GetCommentsAfterID returns up to limit comments with an ID above afterID, by ID, to walk
+// through every comment in batches
+func (r *commentRepo) GetCommentsAfterID(ctx context.Context, afterID uint, limit int) ([]entities.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var comments []entities.Comment
+	err := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id").Limit(limit).Find(&comments).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return comments, nil
+}
+
+// GetCommentsWithoutContentHTML returns comments created before Markdown rendering
+func (r *commentRepo) GetCommentsWithoutContentHTML(ctx context.Context, limit int) ([]entities.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var comments []entities.Comment
+	err := r.db.WithContext(ctx).Where("content_html IS NULL").Order("id").Limit(limit).Find(&comments).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return comments, nil
+}
+
+// SetContentHTML stores the rendered content of a comment
+func (r *commentRepo) SetContentHTML(ctx context.Context, id uint, html string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Model(&entities.Comment{}).Where("id = ?", id).UpdateColumn("content_html", html).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
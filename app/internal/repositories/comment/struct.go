@@ -5,9 +5,11 @@ import (
 )
 
 type Comment struct {
-	ID        uint      `gorm:"primary_key"`
-	PostID    uint      `gorm:"not null;index"`
-	AuthorID  uint      `gorm:"not null"`
-	Content   string    `gorm:"type:text;not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+	ID       uint   `gorm:"primary_key"`
+	PostID   uint   `gorm:"not null;index"`
+	AuthorID uint   `gorm:"not null"`
+	Content  string `gorm:"type:text;not null;index:,class:FULLTEXT"`
+	// ContentHTML is null for comments created before Markdown rendering, until they are backfilled
+	ContentHTML *string   `gorm:"type:text"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index"`
 }
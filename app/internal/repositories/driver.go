@@ -3,8 +3,17 @@ package repositories
 import (
 	"fmt"
 
+	apikey "app/internal/repositories/apikey"
+	category "app/internal/repositories/category"
 	comment "app/internal/repositories/comment"
+	identity "app/internal/repositories/identity"
+	loginattempt "app/internal/repositories/loginattempt"
+	media "app/internal/repositories/media"
+	passwordreset "app/internal/repositories/passwordreset"
 	post "app/internal/repositories/post"
+	recoverycode "app/internal/repositories/recoverycode"
+	session "app/internal/repositories/session"
+	tag "app/internal/repositories/tag"
 	user "app/internal/repositories/user"
 
 	"gorm.io/driver/mysql"
@@ -38,7 +47,25 @@ func InitDB(c DBConfig) *gorm.DB {
 	DB.AutoMigrate(
 		&user.User{},
 		&post.Post{},
+		&post.PostSlug{},
+		&post.PostRevision{},
+		&tag.Tag{},
+		&tag.PostTag{},
+		&category.Category{},
 		&comment.Comment{},
+		&session.Session{},
+		&passwordreset.PasswordReset{},
+		&loginattempt.LoginAttempt{},
+		&loginattempt.LoginCounter{},
+		&recoverycode.RecoveryCode{},
+		&apikey.APIKey{},
+		&identity.UserIdentity{},
+		&media.Media{},
 	)
+
+	// Posts from before the publishing workflow count as published when they were created
+	DB.Model(&post.Post{}).Where("status = ? AND published_at IS NULL", "published").
+		Update("published_at", gorm.Expr("created_at"))
+
 	return DB
 }
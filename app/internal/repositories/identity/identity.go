@@ -0,0 +1,58 @@
+package identity
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=IdentityRepository --output=mocks --outpkg=mocks
+type IdentityRepository interface {
+	FindByProviderSubject(ctx context.Context, provider, subject string) (entities.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *entities.UserIdentity) error
+}
+
+type identityRepository struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewIdentityRepository(db *gorm.DB, timeout time.Duration) IdentityRepository {
+	return &identityRepository{db: db, ContextTimeout: timeout}
+}
+
+// FindByProviderSubject returns the link of the provider account to a local user
+func (r *identityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (entities.UserIdentity, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var identity entities.UserIdentity
+	if err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.UserIdentity{}, commons.ErrNotFound
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.UserIdentity{}, commons.ErrTimeout
+		}
+		return entities.UserIdentity{}, err
+	}
+	return identity, nil
+}
+
+// CreateIdentity inserts a new link into the database
+func (r *identityRepository) CreateIdentity(ctx context.Context, identity *entities.UserIdentity) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package identity
+
+import (
+	"time"
+)
+
+type UserIdentity struct {
+	ID        uint      `gorm:"primary_key"`
+	UserID    uint      `gorm:"not null;index"`
+	Provider  string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_subject"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_subject"`
+	Email     string    `gorm:"type:varchar(255)"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,121 @@
+package loginattempt
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=LoginAttemptRepository --output=mocks --outpkg=mocks
+type LoginAttemptRepository interface {
+	RecordAttempt(ctx context.Context, attempt *entities.LoginAttempt) error
+	GetCounter(ctx context.Context, key string) (entities.LoginCounter, error)
+	IncrementFailures(ctx context.Context, key string, at time.Time) (entities.LoginCounter, error)
+	LockUntil(ctx context.Context, key string, until time.Time) error
+	ResetCounter(ctx context.Context, key string) error
+}
+
+type loginAttemptRepository struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewLoginAttemptRepository(db *gorm.DB, timeout time.Duration) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db, ContextTimeout: timeout}
+}
+
+// RecordAttempt appends a login attempt to the audit log
+func (r *loginAttemptRepository) RecordAttempt(ctx context.Context, attempt *entities.LoginAttempt) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).Create(attempt).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// GetCounter returns the counter of a key, a key without failures returns an empty counter
+func (r *loginAttemptRepository) GetCounter(ctx context.Context, key string) (entities.LoginCounter, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var counter entities.LoginCounter
+	if err := r.db.WithContext(ctx).Where("`key` = ?", key).First(&counter).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.LoginCounter{Key: key}, nil
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.LoginCounter{}, commons.ErrTimeout
+		}
+		return entities.LoginCounter{}, err
+	}
+	return counter, nil
+}
+
+// IncrementFailures atomically adds a failure to the counter of a key and returns the new state
+func (r *loginAttemptRepository) IncrementFailures(ctx context.Context, key string, at time.Time) (entities.LoginCounter, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	counter := entities.LoginCounter{Key: key, Failures: 1, LastFailureAt: at}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("failures + 1"),
+			"last_failure_at": at,
+		}),
+	}).Create(&counter).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.LoginCounter{}, commons.ErrTimeout
+		}
+		return entities.LoginCounter{}, err
+	}
+
+	if err := r.db.WithContext(ctx).Where("`key` = ?", key).First(&counter).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.LoginCounter{}, commons.ErrTimeout
+		}
+		return entities.LoginCounter{}, err
+	}
+	return counter, nil
+}
+
+// LockUntil locks a key until the given time
+func (r *loginAttemptRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Model(&entities.LoginCounter{}).Where("`key` = ?", key).Update("locked_until", until).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// ResetCounter clears the failures and any lock of a key
+func (r *loginAttemptRepository) ResetCounter(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Where("`key` = ?", key).Delete(&entities.LoginCounter{}).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
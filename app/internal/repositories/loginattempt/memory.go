@@ -0,0 +1,83 @@
+package loginattempt
+
+import (
+	"app/internal/entities"
+	"context"
+	"sync"
+	"time"
+)
+
+// maxMemoryAttempts bounds the in-memory audit log, the oldest attempts are dropped first
+const maxMemoryAttempts = 10000
+
+type memoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts []entities.LoginAttempt
+	counters map[string]entities.LoginCounter
+	nextID   uint
+}
+
+// NewMemoryLoginAttemptRepository keeps counters and attempts in process memory. It suits
+// tests and single-instance deployments, counters are not shared between replicas.
+func NewMemoryLoginAttemptRepository() LoginAttemptRepository {
+	return &memoryLoginAttemptRepository{counters: map[string]entities.LoginCounter{}}
+}
+
+func (r *memoryLoginAttemptRepository) RecordAttempt(ctx context.Context, attempt *entities.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	attempt.ID = r.nextID
+	if attempt.CreatedAt.IsZero() {
+		attempt.CreatedAt = time.Now()
+	}
+	r.attempts = append(r.attempts, *attempt)
+	if len(r.attempts) > maxMemoryAttempts {
+		r.attempts = r.attempts[len(r.attempts)-maxMemoryAttempts:]
+	}
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) GetCounter(ctx context.Context, key string) (entities.LoginCounter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if counter, ok := r.counters[key]; ok {
+		return counter, nil
+	}
+	return entities.LoginCounter{Key: key}, nil
+}
+
+func (r *memoryLoginAttemptRepository) IncrementFailures(ctx context.Context, key string, at time.Time) (entities.LoginCounter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counter := r.counters[key]
+	counter.Key = key
+	counter.Failures++
+	counter.LastFailureAt = at
+	r.counters[key] = counter
+	return counter, nil
+}
+
+func (r *memoryLoginAttemptRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counter, ok := r.counters[key]
+	if !ok {
+		return nil
+	}
+	counter.LockedUntil = &until
+	r.counters[key] = counter
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) ResetCounter(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.counters, key)
+	return nil
+}
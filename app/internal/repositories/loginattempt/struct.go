@@ -0,0 +1,22 @@
+package loginattempt
+
+import (
+	"time"
+)
+
+type LoginAttempt struct {
+	ID        uint   `gorm:"primary_key"`
+	Email     string `gorm:"type:varchar(255);not null;index"`
+	IP        string `gorm:"type:varchar(45);not null;index"`
+	UserID    *uint
+	Success   bool      `gorm:"not null"`
+	Reason    string    `gorm:"type:varchar(50)"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+}
+
+type LoginCounter struct {
+	Key           string    `gorm:"type:varchar(300);primary_key"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+}
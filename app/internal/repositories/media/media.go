@@ -0,0 +1,153 @@
+package media
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=MediaRepository --output=mocks --outpkg=mocks
+type MediaRepository interface {
+	CreateMedia(ctx context.Context, media *entities.Media, quota int64) error
+	GetMediaByID(ctx context.Context, id uint) (*entities.Media, error)
+	GetMediaByOwner(ctx context.Context, ownerID uint, limit, offset int) ([]entities.Media, error)
+	SumSizeByOwner(ctx context.Context, ownerID uint) (int64, error)
+	DeleteMedia(ctx context.Context, id uint) error
+}
+
+type mediaRepo struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewMediaRepository(db *gorm.DB, timeout time.Duration) MediaRepository {
+	return &mediaRepo{db: db, ContextTimeout: timeout}
+}
+
+// CreateMedia inserts the record of an uploaded file, unless it would take the uploads of
+// its owner over quota bytes, in which case commons.ErrMediaQuotaExceeded is returned. The
+// owner's user row is locked while checking, so concurrent uploads can not all pass.
+func (r *mediaRepo) CreateMedia(ctx context.Context, media *entities.Media, quota int64) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var owner entities.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&owner, media.OwnerID).Error; err != nil {
+			return err
+		}
+
+		var used int64
+		err := tx.Model(&entities.Media{}).Where("owner_id = ?", media.OwnerID).
+			Select("COALESCE(SUM(size), 0)").Scan(&used).Error
+		if err != nil {
+			return err
+		}
+		if used+media.Size > quota {
+			return commons.ErrMediaQuotaExceeded
+		}
+		return tx.Create(media).Error
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return commons.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetMediaByID returns an uploaded file by its ID
+func (r *mediaRepo) GetMediaByID(ctx context.Context, id uint) (*entities.Media, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var media entities.Media
+	if err := r.db.WithContext(ctx).First(&media, id).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commons.ErrNotFound
+		}
+		return nil, err
+	}
+	return &media, nil
+}
+
+// GetMediaByOwner returns the files uploaded by a user with pagination, latest first
+func (r *mediaRepo) GetMediaByOwner(ctx context.Context, ownerID uint, limit, offset int) ([]entities.Media, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var media []entities.Media
+	err := r.db.WithContext(ctx).Where("owner_id = ?", ownerID).
+		Order("created_at desc, id desc").Limit(limit).Offset(offset).Find(&media).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return media, nil
+}
+
+// SumSizeByOwner returns how many bytes a user has uploaded. It does not lock anything, the
+// quota is only enforced by CreateMedia.
+func (r *mediaRepo) SumSizeByOwner(ctx context.Context, ownerID uint) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var size int64
+	err := r.db.WithContext(ctx).Model(&entities.Media{}).Where("owner_id = ?", ownerID).
+		Select("COALESCE(SUM(size), 0)").Scan(&size).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, commons.ErrTimeout
+		}
+		return 0, err
+	}
+	return size, nil
+}
+
+// DeleteMedia deletes the record of an uploaded file, unless a post, those in the trash
+// included, has it as cover, in which case commons.ErrMediaInUse is returned. The row is
+// locked while checking, posts lock it too when they take it as cover.
+func (r *mediaRepo) DeleteMedia(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var media entities.Media
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&media, id).Error; err != nil {
+			return err
+		}
+
+		var uses int64
+		if err := tx.Unscoped().Model(&entities.Post{}).Where("cover_id = ?", id).Count(&uses).Error; err != nil {
+			return err
+		}
+		if uses > 0 {
+			return commons.ErrMediaInUse
+		}
+		return tx.Delete(&entities.Media{}, id).Error
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return commons.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
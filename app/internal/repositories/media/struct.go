@@ -0,0 +1,16 @@
+package media
+
+import (
+	"time"
+)
+
+type Media struct {
+	ID          uint      `gorm:"primary_key"`
+	OwnerID     uint      `gorm:"not null;index"`
+	Key         string    `gorm:"type:varchar(255);not null;uniqueIndex"`
+	URL         string    `gorm:"type:varchar(500);not null"`
+	Filename    string    `gorm:"type:varchar(255);not null"`
+	ContentType string    `gorm:"type:varchar(100);not null"`
+	Size        int64     `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
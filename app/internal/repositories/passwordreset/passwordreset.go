@@ -0,0 +1,80 @@
+package passwordreset
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=PasswordResetRepository --output=mocks --outpkg=mocks
+type PasswordResetRepository interface {
+	CreatePasswordReset(ctx context.Context, reset *entities.PasswordReset) error
+	FindByTokenHash(ctx context.Context, hash string) (entities.PasswordReset, error)
+	MarkUsed(ctx context.Context, id uint) error
+}
+
+type passwordResetRepository struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewPasswordResetRepository(db *gorm.DB, timeout time.Duration) PasswordResetRepository {
+	return &passwordResetRepository{db: db, ContextTimeout: timeout}
+}
+
+// CreatePasswordReset inserts a new reset token into the database
+func (r *passwordResetRepository) CreatePasswordReset(ctx context.Context, reset *entities.PasswordReset) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).Create(reset).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// FindByTokenHash returns the reset token with the given hash
+func (r *passwordResetRepository) FindByTokenHash(ctx context.Context, hash string) (entities.PasswordReset, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var reset entities.PasswordReset
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&reset).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.PasswordReset{}, commons.ErrNotFound
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.PasswordReset{}, commons.ErrTimeout
+		}
+		return entities.PasswordReset{}, err
+	}
+	return reset, nil
+}
+
+// MarkUsed consumes a reset token. It only succeeds for a token that has not been
+// used yet, so the same token can not be redeemed twice concurrently.
+func (r *passwordResetRepository) MarkUsed(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	res := r.db.WithContext(ctx).Model(&entities.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return commons.ErrInvalidResetToken
+	}
+	return nil
+}
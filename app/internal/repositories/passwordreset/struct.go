@@ -0,0 +1,14 @@
+package passwordreset
+
+import (
+	"time"
+)
+
+type PasswordReset struct {
+	ID        uint      `gorm:"primary_key"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
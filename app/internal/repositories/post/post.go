@@ -5,18 +5,39 @@ import (
 	"app/internal/entities"
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 //go:generate mockery --name=PostRepository --output=mocks --outpkg=mocks
 type PostRepository interface {
 	CreatePost(ctx context.Context, post *entities.Post) error
-	GetAllPosts(ctx context.Context, limit, offset int) ([]entities.Post, error)
+	GetAllPosts(ctx context.Context, filter entities.PostFilter, limit, offset int) ([]entities.Post, error)
 	GetPostById(ctx context.Context, id uint) (*entities.Post, error)
 	UpdatePost(ctx context.Context, post *entities.Post) error
 	DeletePost(ctx context.Context, id uint) error
+	GetPostsByAuthor(ctx context.Context, authorID uint, status string, limit, offset int) ([]entities.Post, error)
+	CountPostsByAuthor(ctx context.Context, authorID uint, status string) (int64, error)
+	GetAllPostsByAuthor(ctx context.Context, authorID uint) ([]entities.Post, error)
+	PublishDuePosts(ctx context.Context, now time.Time, limit int) ([]uint, error)
+	GetPostBySlug(ctx context.Context, slug string) (*entities.Post, error)
+	GetPostIDByOldSlug(ctx context.Context, slug string) (uint, error)
+	FindSlugsWithPrefix(ctx context.Context, prefix string, excludePostID uint) ([]string, error)
+	ChangeSlug(ctx context.Context, id uint, oldSlug, newSlug string) error
+	GetPostsWithoutSlug(ctx context.Context, limit int) ([]entities.Post, error)
+	GetPostsAfterID(ctx context.Context, afterID uint, limit int) ([]entities.Post, error)
+	UpdatePostWithRevision(ctx context.Context, post *entities.Post, revision *entities.PostRevision) error
+	GetRevisions(ctx context.Context, postID uint) ([]entities.PostRevision, error)
+	GetRevision(ctx context.Context, postID, number uint) (entities.PostRevision, error)
+	GetTrashedPostByID(ctx context.Context, id uint) (*entities.Post, error)
+	GetTrashedPostsByAuthor(ctx context.Context, authorID uint, limit, offset int) ([]entities.Post, error)
+	RestorePost(ctx context.Context, id uint) error
+	PurgeDeletedPosts(ctx context.Context, before time.Time, limit int) ([]uint, error)
+	GetPostsWithoutContentHTML(ctx context.Context, limit int) ([]entities.Post, error)
+	SetContentHTML(ctx context.Context, id uint, html string) error
 }
 
 type postRepo struct {
@@ -31,12 +52,34 @@ func NewPostRepository(db *gorm.DB, timeout time.Duration) PostRepository {
 	}
 }
 
-// CreatePost inserts a new post into the database
+// CreatePost inserts a new post into the database with its tags, creating the tags that
+// do not exist yet, and its first revision
 func (r *postRepo) CreatePost(ctx context.Context, post *entities.Post) error {
 	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
 	defer cancel()
 
-	err := r.db.WithContext(ctx).Create(post).Error
+	post.Version = 1
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockCover(tx, post.CoverID); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Create(post).Error; err != nil {
+			return err
+		}
+		tags, err := replaceTags(tx, post.ID, post.Tags)
+		post.Tags = tags
+		if err != nil {
+			return err
+		}
+		return tx.Create(&entities.PostRevision{
+			PostID:    post.ID,
+			Number:    1,
+			Title:     post.Title,
+			Content:   post.Content,
+			EditorID:  post.AuthorID,
+			CreatedAt: post.CreatedAt,
+		}).Error
+	})
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return commons.ErrTimeout
@@ -46,13 +89,24 @@ func (r *postRepo) CreatePost(ctx context.Context, post *entities.Post) error {
 	return nil
 }
 
-// GetAllPosts returns all posts with pagination
-func (r *postRepo) GetAllPosts(ctx context.Context, limit, offset int) ([]entities.Post, error) {
+// GetAllPosts returns the published posts matching filter with pagination, latest published first
+func (r *postRepo) GetAllPosts(ctx context.Context, filter entities.PostFilter, limit, offset int) ([]entities.Post, error) {
 	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
 	defer cancel()
 
+	query := r.db.WithContext(ctx).Where("status = ?", entities.PostStatusPublished)
+	if filter.TagSlug != "" {
+		tagged := r.db.Table("post_tags").Select("post_tags.post_id").
+			Joins("JOIN tags ON tags.id = post_tags.tag_id").
+			Where("tags.slug = ?", filter.TagSlug)
+		query = query.Where("id IN (?)", tagged)
+	}
+	if filter.CategoryIDs != nil {
+		query = query.Where("category_id IN ?", filter.CategoryIDs)
+	}
+
 	var posts []entities.Post
-	err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Order("created_at desc").Preload("Author").Find(&posts).Error
+	err := query.Limit(limit).Offset(offset).Order("published_at desc").Preload("Author").Preload("Category").Preload("Cover").Preload("Tags").Find(&posts).Error
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return nil, commons.ErrTimeout
@@ -68,7 +122,7 @@ func (r *postRepo) GetPostById(ctx context.Context, id uint) (*entities.Post, er
 	defer cancel()
 
 	var post entities.Post
-	err := r.db.WithContext(ctx).Preload("Author").First(&post, id).Error
+	err := r.db.WithContext(ctx).Preload("Author").Preload("Category").Preload("Cover").Preload("Tags").First(&post, id).Error
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return nil, commons.ErrTimeout
@@ -81,12 +135,17 @@ func (r *postRepo) GetPostById(ctx context.Context, id uint) (*entities.Post, er
 	return &post, nil
 }
 
-// UpdatePost updates an existing post
+// UpdatePost updates an existing post and replaces its tags with post.Tags. A new slug is
+// changed in the same transaction, keeping the old one for redirects. The update only goes
+// through while the stored post still has post.Version, otherwise commons.ErrVersionMismatch
+// is returned and nothing is changed.
 func (r *postRepo) UpdatePost(ctx context.Context, post *entities.Post) error {
 	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
 	defer cancel()
 
-	err := r.db.WithContext(ctx).Save(post).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return savePost(tx, post)
+	})
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return commons.ErrTimeout
@@ -96,12 +155,242 @@ func (r *postRepo) UpdatePost(ctx context.Context, post *entities.Post) error {
 	return nil
 }
 
-// DeletePost deletes a post by its ID
+// DeletePost moves a post to the trash. Its comments, tags and revisions are kept until
+// the post is purged.
 func (r *postRepo) DeletePost(ctx context.Context, id uint) error {
 	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
 	defer cancel()
 
-	err := r.db.WithContext(ctx).Delete(&Post{}, id).Error
+	err := r.db.WithContext(ctx).Delete(&entities.Post{}, id).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// GetPostsByAuthor returns the posts of an author with pagination, newest first. An empty
+// status returns the posts of every status.
+func (r *postRepo) GetPostsByAuthor(ctx context.Context, authorID uint, status string, limit, offset int) ([]entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	query := r.db.WithContext(ctx).Where("author_id = ?", authorID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var posts []entities.Post
+	err := query.Limit(limit).Offset(offset).Order("created_at desc").Preload("Category").Preload("Cover").Preload("Tags").Find(&posts).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return posts, nil
+}
+
+// CountPostsByAuthor returns the number of posts written by an author, of every status
+// when status is empty
+func (r *postRepo) CountPostsByAuthor(ctx context.Context, authorID uint, status string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	query := r.db.WithContext(ctx).Model(&entities.Post{}).Where("author_id = ?", authorID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, commons.ErrTimeout
+		}
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetAllPostsByAuthor returns every post of an author, those in the trash included, oldest first
+func (r *postRepo) GetAllPostsByAuthor(ctx context.Context, authorID uint) ([]entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var posts []entities.Post
+	err := r.db.WithContext(ctx).Unscoped().Where("author_id = ?", authorID).Order("created_at asc").Preload("Category").Preload("Cover").Preload("Tags").Find(&posts).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return posts, nil
+}
+
+// PublishDuePosts publishes up to limit scheduled posts whose publish time has passed and
+// returns their IDs. The rows are locked with SKIP LOCKED, so replicas running at the same
+// time each pick different posts instead of waiting on or publishing the same ones.
+func (r *postRepo) PublishDuePosts(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var ids []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.Post{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND publish_at <= ?", entities.PostStatusScheduled, now).
+			Order("publish_at").Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		// MySQL applies the assignments in order, publish_at has to be copied before it is cleared
+		return tx.Exec("UPDATE posts SET status = ?, published_at = publish_at, publish_at = NULL, updated_at = ?, version = version + 1 WHERE id IN ? AND status = ?",
+			entities.PostStatusPublished, now, ids, entities.PostStatusScheduled).Error
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetPostBySlug returns the post whose current slug is the given one
+func (r *postRepo) GetPostBySlug(ctx context.Context, slug string) (*entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var post entities.Post
+	err := r.db.WithContext(ctx).Preload("Author").Preload("Category").Preload("Cover").Preload("Tags").Where("slug = ?", slug).First(&post).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commons.ErrNotFound
+		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+// GetPostIDByOldSlug returns the post a previous slug belonged to
+func (r *postRepo) GetPostIDByOldSlug(ctx context.Context, slug string) (uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var old entities.PostSlug
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&old).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, commons.ErrNotFound
+		}
+		return 0, err
+	}
+	return old.PostID, nil
+}
+
+// FindSlugsWithPrefix returns the current and previous slugs starting with prefix, except
+// those of the given post, which may take any of its own slugs back. Posts in the trash keep
+// their slugs, so they can be restored.
+func (r *postRepo) FindSlugsWithPrefix(ctx context.Context, prefix string, excludePostID uint) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	// Slugs may contain underscores, which LIKE would take for any character
+	pattern := escapeLike(prefix) + "%"
+	var current, previous []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&entities.Post{}).
+		Where("slug LIKE ? ESCAPE '!' AND id <> ?", pattern, excludePostID).
+		Pluck("slug", &current).Error
+	if err == nil {
+		err = r.db.WithContext(ctx).Model(&entities.PostSlug{}).
+			Where("slug LIKE ? ESCAPE '!' AND post_id <> ?", pattern, excludePostID).
+			Pluck("slug", &previous).Error
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return append(current, previous...), nil
+}
+
+// escapeLike makes s match itself in a LIKE pattern with ESCAPE '!'
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// ChangeSlug replaces the slug of a post and keeps the old one for redirects. An empty
+// oldSlug sets the first slug of a post that has none. Nothing is changed when the post
+// no longer has oldSlug, because someone else changed it in the meantime.
+func (r *postRepo) ChangeSlug(ctx context.Context, id uint, oldSlug, newSlug string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceSlug(tx, id, oldSlug, newSlug)
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// GetPostsWithoutSlug returns posts created before slugs existed
+func (r *postRepo) GetPostsWithoutSlug(ctx context.Context, limit int) ([]entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var posts []entities.Post
+	err := r.db.WithContext(ctx).Where("slug IS NULL").Order("id").Limit(limit).Find(&posts).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return posts, nil
+}
+
+// GetPostsWithoutContentHTML returns posts, those in the trash included, created before
+// Markdown rendering
+func (r *postRepo) GetPostsWithoutContentHTML(ctx context.Context, limit int) ([]entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var posts []entities.Post
+	err := r.db.WithContext(ctx).Unscoped().Where("content_html IS NULL").Order("id").Limit(limit).Find(&posts).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return posts, nil
+}
+
+// SetContentHTML stores the rendered content of a post. Neither the version nor the update
+// time change, the post itself stays the same.
+func (r *postRepo) SetContentHTML(ctx context.Context, id uint, html string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Unscoped().Model(&entities.Post{}).Where("id = ?", id).UpdateColumn("content_html", html).Error
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return commons.ErrTimeout
@@ -110,3 +399,322 @@ func (r *postRepo) DeletePost(ctx context.Context, id uint) error {
 	}
 	return nil
 }
+
+// GetPostsAfterID returns up to limit posts of every status with an ID above afterID, by ID,
+// to walk through every post in batches
+func (r *postRepo) GetPostsAfterID(ctx context.Context, afterID uint, limit int) ([]entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var posts []entities.Post
+	err := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id").Limit(limit).Preload("Tags").Find(&posts).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return posts, nil
+}
+
+// UpdatePostWithRevision updates a post like UpdatePost and stores its new title and
+// content as the next revision. The post row is locked meanwhile, so concurrent updates get
+// consecutive numbers. A post from before revisions existed first gets its stored version
+// as revision 1.
+func (r *postRepo) UpdatePostWithRevision(ctx context.Context, post *entities.Post, revision *entities.PostRevision) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored entities.Post
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "title", "content", "author_id", "updated_at").
+			First(&stored, post.ID).Error
+		if err != nil {
+			return err
+		}
+
+		var last uint
+		err = tx.Model(&entities.PostRevision{}).Where("post_id = ?", post.ID).
+			Select("COALESCE(MAX(number), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		if last == 0 {
+			last = 1
+			err := tx.Create(&entities.PostRevision{
+				PostID:    post.ID,
+				Number:    last,
+				Title:     stored.Title,
+				Content:   stored.Content,
+				EditorID:  stored.AuthorID,
+				CreatedAt: stored.UpdatedAt,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := savePost(tx, post); err != nil {
+			return err
+		}
+		revision.PostID = post.ID
+		revision.Number = last + 1
+		revision.Title = post.Title
+		revision.Content = post.Content
+		return tx.Create(revision).Error
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return commons.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetRevisions returns the revisions of a post, newest first
+func (r *postRepo) GetRevisions(ctx context.Context, postID uint) ([]entities.PostRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var revisions []entities.PostRevision
+	err := r.db.WithContext(ctx).Where("post_id = ?", postID).Order("number desc").Find(&revisions).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetRevision returns a revision of a post by its number
+func (r *postRepo) GetRevision(ctx context.Context, postID, number uint) (entities.PostRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var revision entities.PostRevision
+	err := r.db.WithContext(ctx).Where("post_id = ? AND number = ?", postID, number).First(&revision).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.PostRevision{}, commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.PostRevision{}, commons.ErrNotFound
+		}
+		return entities.PostRevision{}, err
+	}
+	return revision, nil
+}
+
+// GetTrashedPostByID returns a post in the trash by its ID
+func (r *postRepo) GetTrashedPostByID(ctx context.Context, id uint) (*entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var post entities.Post
+	err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").
+		Preload("Author").Preload("Category").Preload("Cover").Preload("Tags").First(&post, id).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, commons.ErrNotFound
+		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+// GetTrashedPostsByAuthor returns the posts of an author in the trash, last deleted first
+func (r *postRepo) GetTrashedPostsByAuthor(ctx context.Context, authorID uint, limit, offset int) ([]entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var posts []entities.Post
+	err := r.db.WithContext(ctx).Unscoped().Where("author_id = ? AND deleted_at IS NOT NULL", authorID).
+		Limit(limit).Offset(offset).Order("deleted_at desc").Preload("Category").Preload("Cover").Preload("Tags").Find(&posts).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return posts, nil
+}
+
+// RestorePost takes a post out of the trash
+func (r *postRepo) RestorePost(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	res := r.db.WithContext(ctx).Unscoped().Model(&entities.Post{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if res.Error != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return commons.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedPosts deletes up to limit posts that went to the trash before the given time
+// for good, with their comments, tags, revisions and previous slugs, and returns their IDs
+func (r *postRepo) PurgeDeletedPosts(ctx context.Context, before time.Time, limit int) ([]uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var ids []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&entities.Post{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("deleted_at < ?", before).
+			Order("deleted_at").Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		owned := []interface{}{
+			&entities.Comment{},
+			&entities.PostTag{},
+			&entities.PostRevision{},
+			&entities.PostSlug{},
+		}
+		for _, model := range owned {
+			if err := tx.Where("post_id IN ?", ids).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&entities.Post{}, ids).Error
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return ids, nil
+}
+
+// savePost saves every column of a post and replaces its tags. The row is only updated
+// while it still has post.Version, which is then incremented. A changed slug goes through
+// replaceSlug, so the old one keeps redirecting.
+func savePost(tx *gorm.DB, post *entities.Post) error {
+	if err := lockCover(tx, post.CoverID); err != nil {
+		return err
+	}
+
+	// Save would insert the post again when no row matches, hence Updates
+	version := post.Version
+	post.Version++
+	res := tx.Model(post).Where("version = ?", version).
+		Select("*").Omit("Slug", clause.Associations).Updates(post)
+	if res.Error == nil && res.RowsAffected == 0 {
+		res.Error = commons.ErrVersionMismatch
+	}
+	if res.Error != nil {
+		post.Version = version
+		return res.Error
+	}
+
+	// The row is locked by the update, so the stored slug can not change meanwhile
+	if post.Slug != "" {
+		var stored entities.Post
+		if err := tx.Select("id", "slug").First(&stored, post.ID).Error; err != nil {
+			return err
+		}
+		if stored.Slug != post.Slug {
+			if err := replaceSlug(tx, post.ID, stored.Slug, post.Slug); err != nil {
+				return err
+			}
+		}
+	}
+
+	tags, err := replaceTags(tx, post.ID, post.Tags)
+	post.Tags = tags
+	return err
+}
+
+// lockCover keeps the cover of a post from being deleted until the transaction ends, and
+// fails with commons.ErrInvalidCover when it is gone already
+func lockCover(tx *gorm.DB, coverID *uint) error {
+	if coverID == nil {
+		return nil
+	}
+	var media entities.Media
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Select("id").First(&media, *coverID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return commons.ErrInvalidCover
+	}
+	return err
+}
+
+// replaceSlug replaces the slug of a post and keeps the old one for redirects. An empty
+// oldSlug sets the first slug of a post that has none. Nothing is changed when the post no
+// longer has oldSlug.
+func replaceSlug(tx *gorm.DB, id uint, oldSlug, newSlug string) error {
+	query := tx.Model(&entities.Post{}).Where("id = ?", id)
+	if oldSlug == "" {
+		query = query.Where("slug IS NULL")
+	} else {
+		query = query.Where("slug = ?", oldSlug)
+	}
+	res := query.Update("slug", newSlug)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return res.Error
+	}
+
+	// A post that gets one of its previous slugs back no longer needs the redirect
+	if err := tx.Where("post_id = ? AND slug = ?", id, newSlug).Delete(&entities.PostSlug{}).Error; err != nil {
+		return err
+	}
+	if oldSlug == "" {
+		return nil
+	}
+	return tx.Create(&entities.PostSlug{PostID: id, Slug: oldSlug}).Error
+}
+
+// replaceTags makes tags the only tags of a post and returns them with their IDs. Tags are
+// matched by slug, the missing ones are created with their given name.
+func replaceTags(tx *gorm.DB, postID uint, tags []entities.Tag) ([]entities.Tag, error) {
+	if err := tx.Where("post_id = ?", postID).Delete(&entities.PostTag{}).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return []entities.Tag{}, nil
+	}
+
+	slugs := make([]string, len(tags))
+	missing := make([]entities.Tag, len(tags))
+	for i, tag := range tags {
+		slugs[i] = tag.Slug
+		missing[i] = entities.Tag{Name: tag.Name, Slug: tag.Slug}
+	}
+	// Another post may create the same tag concurrently, existing slugs are skipped
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+		return nil, err
+	}
+
+	var saved []entities.Tag
+	if err := tx.Where("slug IN ?", slugs).Order("name").Find(&saved).Error; err != nil {
+		return nil, err
+	}
+	links := make([]entities.PostTag, len(saved))
+	for i, tag := range saved {
+		links[i] = entities.PostTag{PostID: postID, TagID: tag.ID}
+	}
+	if err := tx.Create(&links).Error; err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
@@ -0,0 +1,19 @@
+package post
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "hello-world", want: "hello-world"},
+		{in: "a_b", want: "a!_b"},
+		{in: "100%!", want: "100!%!!"},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -2,13 +2,48 @@ package post
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Post struct {
+	ID uint `gorm:"primary_key"`
+	// Slug is only null for posts created before slugs existed, until they are backfilled
+	Slug     *string `gorm:"type:varchar(100);uniqueIndex"`
+	Title    string  `gorm:"type:longtext;not null;index:idx_posts_fulltext,class:FULLTEXT"`
+	Content  string  `gorm:"type:text;not null;index:idx_posts_fulltext,class:FULLTEXT"`
+	AuthorID uint    `gorm:"not null;index"`
+	// CategoryID is null for uncategorized posts
+	CategoryID *uint `gorm:"index"`
+	// Posts created before drafts existed were public right away, hence the default
+	Status      string         `gorm:"type:varchar(20);not null;default:published;index:idx_status_published_at;index:idx_status_publish_at"`
+	PublishAt   *time.Time     `gorm:"index:idx_status_publish_at"`
+	PublishedAt *time.Time     `gorm:"index:idx_status_published_at"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime;index"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	// Existing posts start at version 1
+	Version uint `gorm:"not null;default:1"`
+	// ContentHTML is null for posts created before Markdown rendering, until they are backfilled
+	ContentHTML *string `gorm:"type:text"`
+	// CoverID is null for posts without a cover image
+	CoverID *uint `gorm:"index"`
+}
+
+type PostSlug struct {
 	ID        uint      `gorm:"primary_key"`
-	Title     string    `gorm:"not null"`
-	Content   string    `gorm:"type:text;not null"`
-	AuthorID  uint      `gorm:"not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime;index"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	PostID    uint      `gorm:"not null;index"`
+	Slug      string    `gorm:"type:varchar(100);not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+type PostRevision struct {
+	ID           uint   `gorm:"primary_key"`
+	PostID       uint   `gorm:"not null;uniqueIndex:idx_post_revision_number"`
+	Number       uint   `gorm:"not null;uniqueIndex:idx_post_revision_number"`
+	Title        string `gorm:"not null"`
+	Content      string `gorm:"type:text;not null"`
+	EditorID     uint   `gorm:"not null"`
+	RestoredFrom *uint
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
 }
@@ -0,0 +1,22 @@
+package post
+
+import (
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm/schema"
+)
+
+// Untyped string columns with an index become varchar(191), too short for existing titles
+func TestPostTitleColumnType(t *testing.T) {
+	s, err := schema.Parse(&Post{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("schema.Parse() error = %v", err)
+	}
+	dialector := mysql.Dialector{Config: &mysql.Config{}}
+
+	if got := dialector.DataTypeOf(s.LookUpField("Title")); got != "longtext" {
+		t.Errorf("Post.Title column type = %q, want longtext", got)
+	}
+}
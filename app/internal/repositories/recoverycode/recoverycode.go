@@ -0,0 +1,89 @@
+package recoverycode
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=RecoveryCodeRepository --output=mocks --outpkg=mocks
+type RecoveryCodeRepository interface {
+	ReplaceCodes(ctx context.Context, userID uint, hashes []string) error
+	UseCode(ctx context.Context, userID uint, hash string) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+type recoveryCodeRepository struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB, timeout time.Duration) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db, ContextTimeout: timeout}
+}
+
+// ReplaceCodes drops every recovery code of the user and stores the new set
+func (r *recoveryCodeRepository) ReplaceCodes(ctx context.Context, userID uint, hashes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	codes := make([]entities.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, entities.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// UseCode consumes a recovery code of the user. It only succeeds for a code that has
+// not been used yet, so the same code can not be redeemed twice concurrently.
+func (r *recoveryCodeRepository) UseCode(ctx context.Context, userID uint, hash string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	res := r.db.WithContext(ctx).Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return commons.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// DeleteByUserID removes every recovery code of the user
+func (r *recoveryCodeRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
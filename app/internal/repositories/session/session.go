@@ -0,0 +1,139 @@
+package session
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=SessionRepository --output=mocks --outpkg=mocks
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *entities.Session) error
+	FindByID(ctx context.Context, id uint) (entities.Session, error)
+	FindByRefreshTokenHash(ctx context.Context, hash string) (entities.Session, error)
+	RotateRefreshToken(ctx context.Context, id uint, oldHash, newHash string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, id uint) error
+	RevokeAllByUserID(ctx context.Context, userID uint) error
+}
+
+type sessionRepository struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewSessionRepository(db *gorm.DB, timeout time.Duration) SessionRepository {
+	return &sessionRepository{db: db, ContextTimeout: timeout}
+}
+
+// CreateSession inserts a new session into the database
+func (r *sessionRepository) CreateSession(ctx context.Context, session *entities.Session) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	if err := r.db.WithContext(ctx).Create(session).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// FindByID returns a session by its ID
+func (r *sessionRepository) FindByID(ctx context.Context, id uint) (entities.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var session entities.Session
+	if err := r.db.WithContext(ctx).First(&session, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Session{}, commons.ErrNotFound
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.Session{}, commons.ErrTimeout
+		}
+		return entities.Session{}, err
+	}
+	return session, nil
+}
+
+// FindByRefreshTokenHash returns the session currently holding the given refresh token hash
+func (r *sessionRepository) FindByRefreshTokenHash(ctx context.Context, hash string) (entities.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var session entities.Session
+	if err := r.db.WithContext(ctx).Where("refresh_token_hash = ?", hash).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Session{}, commons.ErrNotFound
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.Session{}, commons.ErrTimeout
+		}
+		return entities.Session{}, err
+	}
+	return session, nil
+}
+
+// RotateRefreshToken swaps the refresh token of an active session. The update is
+// conditional on the old hash so two concurrent refreshes cannot both succeed.
+func (r *sessionRepository) RotateRefreshToken(ctx context.Context, id uint, oldHash, newHash string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	res := r.db.WithContext(ctx).Model(&entities.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": newHash,
+			"expires_at":         expiresAt,
+			"updated_at":         time.Now(),
+		})
+	if res.Error != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return commons.ErrSessionRevoked
+	}
+	return nil
+}
+
+// RevokeSession marks a single session as revoked
+func (r *sessionRepository) RevokeSession(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Model(&entities.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// RevokeAllByUserID marks every active session of a user as revoked
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Model(&entities.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package session
+
+import (
+	"time"
+)
+
+type Session struct {
+	ID               uint      `gorm:"primary_key"`
+	UserID           uint      `gorm:"not null;index"`
+	RefreshTokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt        time.Time `gorm:"not null"`
+	RevokedAt        *time.Time
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,13 @@
+package tag
+
+type Tag struct {
+	ID   uint   `gorm:"primary_key"`
+	Name string `gorm:"type:varchar(50);not null"`
+	Slug string `gorm:"type:varchar(100);not null;uniqueIndex"`
+}
+
+// PostTag is the join table between posts and tags
+type PostTag struct {
+	PostID uint `gorm:"primaryKey;autoIncrement:false"`
+	TagID  uint `gorm:"primaryKey;autoIncrement:false;index"`
+}
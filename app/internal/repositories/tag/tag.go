@@ -0,0 +1,47 @@
+package tag
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=TagRepository --output=mocks --outpkg=mocks
+type TagRepository interface {
+	GetTagsWithCounts(ctx context.Context) ([]entities.TagCount, error)
+}
+
+type tagRepository struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+func NewTagRepository(db *gorm.DB, timeout time.Duration) TagRepository {
+	return &tagRepository{db: db, ContextTimeout: timeout}
+}
+
+// GetTagsWithCounts returns the tags of published posts with how many published posts use
+// them, most used first. Tags only used by drafts are left out.
+func (r *tagRepository) GetTagsWithCounts(ctx context.Context) ([]entities.TagCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var tags []entities.TagCount
+	err := r.db.WithContext(ctx).Model(&entities.Tag{}).
+		Select("tags.id, tags.name, tags.slug, COUNT(*) AS post_count").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.status = ? AND posts.deleted_at IS NULL", entities.PostStatusPublished).
+		Group("tags.id, tags.name, tags.slug").
+		Order("post_count desc, tags.name").
+		Scan(&tags).Error
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return tags, nil
+}
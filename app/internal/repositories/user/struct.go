@@ -5,10 +5,18 @@ import (
 )
 
 type User struct {
-	ID           uint      `gorm:"primary_key"`
-	Name         string    `gorm:"type:varchar(100)"`
-	Email        string    `gorm:"unique;not null;uniqueIndex"`
-	PasswordHash string    `gorm:"not null"`
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	ID               uint   `gorm:"primary_key"`
+	Name             string `gorm:"type:varchar(100)"`
+	Email            string `gorm:"unique;not null;uniqueIndex"`
+	PasswordHash     string `gorm:"not null"`
+	Role             string `gorm:"type:varchar(20);not null;default:user"`
+	Bio              string `gorm:"type:text"`
+	AvatarURL        string `gorm:"type:varchar(500)"`
+	EmailVerified    bool   `gorm:"not null;default:false"`
+	EmailVerifiedAt  *time.Time
+	TOTPSecret       string    `gorm:"type:varchar(64)"`
+	TOTPLastUsedStep int64     `gorm:"not null;default:0"`
+	TwoFactorEnabled bool      `gorm:"not null;default:false"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
 }
@@ -5,16 +5,23 @@ import (
 	"app/internal/entities"
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 //go:generate mockery --name=UserRepository --output=mocks --outpkg=mocks
 type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (entities.User, error)
+	FindByID(ctx context.Context, id uint) (entities.User, error)
 	CreateUser(ctx context.Context, user entities.User) error
 	UpdateUser(ctx context.Context, user entities.User) error
+	UpdateProfile(ctx context.Context, id uint, req entities.UpdateProfileRequest) error
+	ClaimTOTPStep(ctx context.Context, id uint, step int64) error
+	DeleteAccount(ctx context.Context, id uint, keepContent bool) ([]string, error)
+	ResetCredentials(ctx context.Context, id uint) error
 }
 
 type userRepository struct {
@@ -44,6 +51,24 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (entitie
 	return user, nil
 }
 
+func (r *userRepository) FindByID(ctx context.Context, id uint) (entities.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var user entities.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.User{}, commons.ErrNotFound
+		}
+		// Check if the context was canceled
+		if ctx.Err() == context.DeadlineExceeded {
+			return entities.User{}, commons.ErrTimeout
+		}
+		return entities.User{}, err
+	}
+	return user, nil
+}
+
 func (r *userRepository) CreateUser(ctx context.Context, user entities.User) error {
 	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
 	defer cancel()
@@ -78,3 +103,183 @@ func (r *userRepository) UpdateUser(ctx context.Context, user entities.User) err
 
 	return nil
 }
+
+// UpdateProfile only writes the profile fields present in the request, so it can not
+// overwrite columns changed concurrently such as the password hash
+func (r *userRepository) UpdateProfile(ctx context.Context, id uint, req entities.UpdateProfileRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	fields := map[string]interface{}{"updated_at": time.Now()}
+	if req.Name != nil {
+		fields["name"] = *req.Name
+	}
+	if req.Bio != nil {
+		fields["bio"] = *req.Bio
+	}
+	if req.AvatarURL != nil {
+		fields["avatar_url"] = *req.AvatarURL
+	}
+
+	if err := r.db.WithContext(ctx).Model(&entities.User{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		// Check if the context was canceled
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ClaimTOTPStep records the time step of an accepted TOTP code. It only succeeds for a
+// step later than the last one used, so a code can not be replayed within its window.
+func (r *userRepository) ClaimTOTPStep(ctx context.Context, id uint, step int64) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	res := r.db.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ? AND totp_last_used_step < ?", id, step).
+		Update("totp_last_used_step", step)
+	if res.Error != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return commons.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// ResetCredentials takes an account over for whoever proved to own its email in another
+// way, like an identity provider. In one transaction the password and two-factor setup are
+// removed, sessions and API keys revoked, and the email is marked verified.
+func (r *userRepository) ResetCredentials(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&entities.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"password_hash":       "",
+			"email_verified":      true,
+			"email_verified_at":   now,
+			"totp_secret":         "",
+			"totp_last_used_step": 0,
+			"two_factor_enabled":  false,
+			"updated_at":          now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&entities.Session{}, &entities.APIKey{}} {
+			if err := tx.Model(model).Where("user_id = ? AND revoked_at IS NULL", id).Update("revoked_at", now).Error; err != nil {
+				return err
+			}
+		}
+		for _, model := range []interface{}{&entities.RecoveryCode{}, &entities.PasswordReset{}} {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return commons.ErrTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteAccount removes the sessions, API keys, uploads and other account data of a user in
+// one transaction. With keepContent the user row is anonymized instead of deleted, so posts
+// and comments stay attributed to a deleted user; otherwise they are deleted as well,
+// including the comments others left on the user's posts. It returns the storage keys of the
+// deleted uploads, whose files are left for the caller to remove.
+func (r *userRepository) DeleteAccount(ctx context.Context, id uint, keepContent bool) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ContextTimeout)
+	defer cancel()
+
+	var keys []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owned := []interface{}{
+			&entities.Session{},
+			&entities.APIKey{},
+			&entities.RecoveryCode{},
+			&entities.UserIdentity{},
+			&entities.PasswordReset{},
+			&entities.LoginAttempt{},
+		}
+		for _, model := range owned {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Posts that are kept, the user's or anyone else's, lose the uploads as covers
+		// Locked like DeleteMedia, so no post can take one of them as cover meanwhile
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&entities.Media{}).
+			Where("owner_id = ?", id).Pluck("key", &keys).Error
+		if err != nil {
+			return err
+		}
+		media := tx.Model(&entities.Media{}).Select("id").Where("owner_id = ?", id)
+		err = tx.Unscoped().Model(&entities.Post{}).Where("cover_id IN (?)", media).Updates(map[string]interface{}{
+			"cover_id": nil,
+			"version":  gorm.Expr("version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("owner_id = ?", id).Delete(&entities.Media{}).Error; err != nil {
+			return err
+		}
+
+		if keepContent {
+			return tx.Model(&entities.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+				"name":                entities.DeletedUserName,
+				"email":               fmt.Sprintf("deleted-%d@deleted.invalid", id),
+				"password_hash":       "",
+				"role":                commons.RoleUser,
+				"bio":                 "",
+				"avatar_url":          "",
+				"email_verified":      false,
+				"email_verified_at":   nil,
+				"totp_secret":         "",
+				"totp_last_used_step": 0,
+				"two_factor_enabled":  false,
+				"updated_at":          time.Now(),
+			}).Error
+		}
+
+		// Posts in the trash go as well
+		posts := tx.Unscoped().Model(&entities.Post{}).Select("id").Where("author_id = ?", id)
+		if err := tx.Where("author_id = ? OR post_id IN (?)", id, posts).Delete(&entities.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN (?)", posts).Delete(&entities.PostTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN (?)", posts).Delete(&entities.PostRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN (?)", posts).Delete(&entities.PostSlug{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("author_id = ?", id).Delete(&entities.Post{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.User{}, id).Error
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+	return keys, nil
+}
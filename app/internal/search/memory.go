@@ -0,0 +1,272 @@
+package search
+
+import (
+	"app/internal/entities"
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BM25 parameters, the usual defaults
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// titleWeight counts every word of a post title this many times
+	titleWeight = 2
+)
+
+type docKey struct {
+	kind string
+	id   uint
+}
+
+type memoryDoc struct {
+	key      docKey
+	postID   uint
+	authorID uint
+	content  string
+	terms    map[string]int
+	length   int
+	// Only set for posts, comments take them from their post
+	title       string
+	slug        string
+	status      string
+	tags        []string
+	publishedAt *time.Time
+	deleted     bool
+	createdAt   time.Time
+}
+
+type memoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[docKey]*memoryDoc
+	postings map[string]map[docKey]int
+	totalLen int
+}
+
+// NewMemoryIndex keeps an inverted index in process memory and ranks with BM25. It suits
+// tests and single-instance deployments, it has to be filled on every start.
+func NewMemoryIndex() Index {
+	return &memoryIndex{
+		docs:     map[docKey]*memoryDoc{},
+		postings: map[string]map[docKey]int{},
+	}
+}
+
+func (i *memoryIndex) IndexPost(ctx context.Context, post entities.Post) error {
+	tags := make([]string, len(post.Tags))
+	for n, tag := range post.Tags {
+		tags[n] = tag.Slug
+	}
+
+	terms := map[string]int{}
+	for _, t := range Tokenize(post.Title) {
+		terms[t] += titleWeight
+	}
+	for _, t := range Tokenize(post.Content) {
+		terms[t]++
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.put(&memoryDoc{
+		key:         docKey{entities.SearchResultPost, post.ID},
+		postID:      post.ID,
+		authorID:    post.AuthorID,
+		content:     post.Content,
+		terms:       terms,
+		title:       post.Title,
+		slug:        post.Slug,
+		status:      post.Status,
+		tags:        tags,
+		publishedAt: post.PublishedAt,
+		deleted:     post.DeletedAt.Valid,
+		createdAt:   post.CreatedAt,
+	})
+	return nil
+}
+
+func (i *memoryIndex) IndexComment(ctx context.Context, comment entities.Comment) error {
+	terms := map[string]int{}
+	for _, t := range Tokenize(comment.Content) {
+		terms[t]++
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.put(&memoryDoc{
+		key:       docKey{entities.SearchResultComment, comment.ID},
+		postID:    comment.PostID,
+		authorID:  comment.AuthorID,
+		content:   comment.Content,
+		terms:     terms,
+		createdAt: comment.CreatedAt,
+	})
+	return nil
+}
+
+// RemovePost removes a post and the comments on it
+func (i *memoryIndex) RemovePost(ctx context.Context, id uint) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for key, doc := range i.docs {
+		if doc.postID == id {
+			i.remove(key)
+		}
+	}
+	return nil
+}
+
+func (i *memoryIndex) RemoveComment(ctx context.Context, id uint) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(docKey{entities.SearchResultComment, id})
+	return nil
+}
+
+func (i *memoryIndex) RemoveAuthor(ctx context.Context, authorID uint) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	posts := map[uint]bool{}
+	for key, doc := range i.docs {
+		if key.kind == entities.SearchResultPost && doc.authorID == authorID {
+			posts[key.id] = true
+		}
+	}
+	for key, doc := range i.docs {
+		if doc.authorID == authorID || posts[doc.postID] {
+			i.remove(key)
+		}
+	}
+	return nil
+}
+
+func (i *memoryIndex) Search(ctx context.Context, query entities.SearchQuery, limit, offset int) ([]entities.SearchResult, error) {
+	terms := Tokenize(query.Text)
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if len(i.docs) == 0 {
+		return []entities.SearchResult{}, nil
+	}
+	n := float64(len(i.docs))
+	avgLen := float64(i.totalLen) / n
+
+	scores := map[docKey]float64{}
+	seen := map[string]bool{}
+	for _, term := range terms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		docs := i.postings[term]
+		idf := math.Log(1 + (n-float64(len(docs))+0.5)/(float64(len(docs))+0.5))
+		for key, tf := range docs {
+			length := float64(i.docs[key].length)
+			scores[key] += idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*(1-bm25B+bm25B*length/avgLen))
+		}
+	}
+
+	results := []entities.SearchResult{}
+	for key, score := range scores {
+		doc := i.docs[key]
+		post := i.docs[docKey{entities.SearchResultPost, doc.postID}]
+		if post == nil || post.status != entities.PostStatusPublished || post.deleted {
+			continue
+		}
+		date := doc.createdAt
+		if key.kind == entities.SearchResultPost && doc.publishedAt != nil {
+			date = *doc.publishedAt
+		}
+		if !matches(query, doc, post, date) {
+			continue
+		}
+
+		results = append(results, entities.SearchResult{
+			Type:     key.kind,
+			ID:       key.id,
+			PostID:   doc.postID,
+			PostSlug: post.slug,
+			Title:    post.title,
+			Snippet:  Snippet(doc.content, terms),
+			AuthorID: doc.authorID,
+			Date:     date,
+			Score:    score,
+		})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Date.After(results[b].Date)
+	})
+	if offset >= len(results) {
+		return []entities.SearchResult{}, nil
+	}
+	results = results[offset:]
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// matches applies the filters of query to a post or comment and the post it belongs to
+func matches(query entities.SearchQuery, doc, post *memoryDoc, date time.Time) bool {
+	if query.AuthorID != 0 && doc.authorID != query.AuthorID {
+		return false
+	}
+	if query.From != nil && date.Before(*query.From) {
+		return false
+	}
+	if query.To != nil && !date.Before(*query.To) {
+		return false
+	}
+	if query.Tag == "" {
+		return true
+	}
+	for _, tag := range post.tags {
+		if tag == query.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// put adds a document or replaces the previous version, the caller holds the write lock
+func (i *memoryIndex) put(doc *memoryDoc) {
+	i.remove(doc.key)
+
+	for term, tf := range doc.terms {
+		doc.length += tf
+		if i.postings[term] == nil {
+			i.postings[term] = map[docKey]int{}
+		}
+		i.postings[term][doc.key] = tf
+	}
+	i.docs[doc.key] = doc
+	i.totalLen += doc.length
+}
+
+// remove drops a document, the caller holds the write lock
+func (i *memoryIndex) remove(key docKey) {
+	doc, ok := i.docs[key]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(i.postings[term], key)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	i.totalLen -= doc.length
+	delete(i.docs, key)
+}
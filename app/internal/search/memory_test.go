@@ -0,0 +1,116 @@
+package search
+
+import (
+	"app/internal/entities"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryIndex_Search(t *testing.T) {
+	ctx := context.TODO()
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 9, d, 12, 0, 0, 0, time.UTC)
+		return &at
+	}
+
+	index := NewMemoryIndex()
+	posts := []entities.Post{
+		{ID: 1, AuthorID: 1, Slug: "golang-generics", Title: "Golang generics", Content: "Type parameters arrived in Go 1.18.", Status: entities.PostStatusPublished, PublishedAt: day(1), Tags: []entities.Tag{{Slug: "go"}}},
+		{ID: 2, AuthorID: 2, Slug: "cooking", Title: "Cooking pasta", Content: "Boil water. Golang is not involved.", Status: entities.PostStatusPublished, PublishedAt: day(5)},
+		{ID: 3, AuthorID: 1, Slug: "draft", Title: "Golang secrets", Content: "Not ready yet.", Status: entities.PostStatusDraft},
+	}
+	for _, post := range posts {
+		index.IndexPost(ctx, post)
+	}
+	index.IndexComment(ctx, entities.Comment{ID: 10, PostID: 2, AuthorID: 3, Content: "I write golang while the pasta cooks", CreatedAt: *day(6)})
+	index.IndexComment(ctx, entities.Comment{ID: 11, PostID: 3, AuthorID: 3, Content: "golang comment on a draft", CreatedAt: *day(6)})
+
+	tests := []struct {
+		name  string
+		query entities.SearchQuery
+		want  []uint
+	}{
+		{name: "title matches rank first", query: entities.SearchQuery{Text: "golang"}, want: []uint{1, 10, 2}},
+		{name: "any of the words", query: entities.SearchQuery{Text: "generics pasta"}, want: []uint{1, 2, 10}},
+		{name: "author", query: entities.SearchQuery{Text: "golang", AuthorID: 3}, want: []uint{10}},
+		{name: "tag", query: entities.SearchQuery{Text: "golang", Tag: "go"}, want: []uint{1}},
+		{name: "date range", query: entities.SearchQuery{Text: "golang", From: day(2), To: day(6)}, want: []uint{2}},
+		{name: "no match", query: entities.SearchQuery{Text: "rust"}, want: []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := index.Search(ctx, tt.query, 10, 0)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			ids := []uint{}
+			for _, r := range got {
+				ids = append(ids, r.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("Search() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("Search() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("comment result", func(t *testing.T) {
+		got, _ := index.Search(ctx, entities.SearchQuery{Text: "golang", AuthorID: 3}, 10, 0)
+		want := entities.SearchResult{
+			Type: entities.SearchResultComment, ID: 10, PostID: 2, PostSlug: "cooking", Title: "Cooking pasta",
+			Snippet: "I write <mark>golang</mark> while the pasta cooks", AuthorID: 3, Date: *day(6),
+		}
+		got[0].Score = 0
+		if got[0] != want {
+			t.Errorf("Search() = %+v, want %+v", got[0], want)
+		}
+	})
+
+	t.Run("removed post takes its comments", func(t *testing.T) {
+		index.RemovePost(ctx, 2)
+		got, _ := index.Search(ctx, entities.SearchQuery{Text: "pasta"}, 10, 0)
+		if len(got) != 0 {
+			t.Errorf("Search() = %+v, want no results", got)
+		}
+	})
+
+	t.Run("published draft", func(t *testing.T) {
+		posts[2].Status = entities.PostStatusPublished
+		index.IndexPost(ctx, posts[2])
+		got, _ := index.Search(ctx, entities.SearchQuery{Text: "secrets"}, 10, 0)
+		if len(got) != 1 || got[0].ID != 3 {
+			t.Errorf("Search() = %+v, want post 3", got)
+		}
+	})
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("filler ", 60)
+
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{name: "marks every match", text: "Go, go and GO!", terms: []string{"go"}, want: "<mark>Go</mark>, <mark>go</mark> and <mark>GO</mark>!"},
+		{name: "escapes html", text: "<b>Go</b> & co", terms: []string{"go"}, want: "&lt;b&gt;<mark>Go</mark>&lt;/b&gt; &amp; co"},
+		{name: "no match", text: "short text", terms: []string{"go"}, want: "short text"},
+		{name: "cuts around the match", text: long + "needle " + long, terms: []string{"needle"}, want: "…" + strings.Repeat("filler ", 7) + "<mark>needle</mark> " + strings.TrimSpace(strings.Repeat("filler ", 20)) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(tt.text, tt.terms); got != tt.want {
+				t.Errorf("Snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
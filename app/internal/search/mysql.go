@@ -0,0 +1,129 @@
+package search
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type mysqlIndex struct {
+	db             *gorm.DB
+	ContextTimeout time.Duration
+}
+
+// NewMySQLIndex searches the posts and comments tables through their FULLTEXT indexes in
+// natural language mode. MySQL keeps those indexes up to date itself, so the Index and
+// Remove methods do nothing.
+func NewMySQLIndex(db *gorm.DB, timeout time.Duration) Index {
+	return &mysqlIndex{db: db, ContextTimeout: timeout}
+}
+
+type mysqlRow struct {
+	Type     string
+	ID       uint
+	PostID   uint
+	PostSlug string
+	Title    string
+	Content  string
+	AuthorID uint
+	Date     time.Time
+	Score    float64
+}
+
+func (i *mysqlIndex) Search(ctx context.Context, query entities.SearchQuery, limit, offset int) ([]entities.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.ContextTimeout)
+	defer cancel()
+
+	postWhere, postArgs := mysqlFilters(query, "p.author_id", "p.published_at", "p.id")
+	commentWhere, commentArgs := mysqlFilters(query, "c.author_id", "c.created_at", "c.post_id")
+
+	sql := `SELECT 'post' AS type, p.id, p.id AS post_id, COALESCE(p.slug, '') AS post_slug, p.title, p.content, p.author_id,
+			p.published_at AS date, MATCH(p.title, p.content) AGAINST (?) AS score
+		FROM posts p
+		WHERE MATCH(p.title, p.content) AGAINST (?) AND p.status = ? AND p.deleted_at IS NULL` + postWhere + `
+		UNION ALL
+		SELECT 'comment', c.id, c.post_id, COALESCE(p.slug, ''), p.title, c.content, c.author_id,
+			c.created_at, MATCH(c.content) AGAINST (?)
+		FROM comments c JOIN posts p ON p.id = c.post_id
+		WHERE MATCH(c.content) AGAINST (?) AND p.status = ? AND p.deleted_at IS NULL` + commentWhere + `
+		ORDER BY score DESC, date DESC
+		LIMIT ? OFFSET ?`
+
+	args := []interface{}{query.Text, query.Text, entities.PostStatusPublished}
+	args = append(args, postArgs...)
+	args = append(args, query.Text, query.Text, entities.PostStatusPublished)
+	args = append(args, commentArgs...)
+	args = append(args, limit, offset)
+
+	var rows []mysqlRow
+	if err := i.db.WithContext(ctx).Raw(sql, args...).Scan(&rows).Error; err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, commons.ErrTimeout
+		}
+		return nil, err
+	}
+
+	terms := Tokenize(query.Text)
+	results := make([]entities.SearchResult, len(rows))
+	for n, row := range rows {
+		results[n] = entities.SearchResult{
+			Type:     row.Type,
+			ID:       row.ID,
+			PostID:   row.PostID,
+			PostSlug: row.PostSlug,
+			Title:    row.Title,
+			Snippet:  Snippet(row.Content, terms),
+			AuthorID: row.AuthorID,
+			Date:     row.Date,
+			Score:    row.Score,
+		}
+	}
+	return results, nil
+}
+
+// mysqlFilters turns the filters of query into extra conditions on the given columns
+func mysqlFilters(query entities.SearchQuery, authorColumn, dateColumn, postColumn string) (string, []interface{}) {
+	var where strings.Builder
+	var args []interface{}
+	if query.AuthorID != 0 {
+		where.WriteString(" AND " + authorColumn + " = ?")
+		args = append(args, query.AuthorID)
+	}
+	if query.From != nil {
+		where.WriteString(" AND " + dateColumn + " >= ?")
+		args = append(args, *query.From)
+	}
+	if query.To != nil {
+		where.WriteString(" AND " + dateColumn + " < ?")
+		args = append(args, *query.To)
+	}
+	if query.Tag != "" {
+		where.WriteString(" AND " + postColumn + " IN (SELECT pt.post_id FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.slug = ?)")
+		args = append(args, query.Tag)
+	}
+	return where.String(), args
+}
+
+func (i *mysqlIndex) IndexPost(ctx context.Context, post entities.Post) error {
+	return nil
+}
+
+func (i *mysqlIndex) RemovePost(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (i *mysqlIndex) IndexComment(ctx context.Context, comment entities.Comment) error {
+	return nil
+}
+
+func (i *mysqlIndex) RemoveComment(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (i *mysqlIndex) RemoveAuthor(ctx context.Context, authorID uint) error {
+	return nil
+}
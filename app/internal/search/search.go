@@ -0,0 +1,53 @@
+package search
+
+import (
+	"app/internal/entities"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Index finds published posts and the comments on them. Posts and comments of every status,
+// and posts in the trash, are handed to it, it decides what may be found.
+//
+//go:generate mockery --name=Index --output=mocks --outpkg=mocks
+type Index interface {
+	Search(ctx context.Context, query entities.SearchQuery, limit, offset int) ([]entities.SearchResult, error)
+	IndexPost(ctx context.Context, post entities.Post) error
+	RemovePost(ctx context.Context, id uint) error
+	IndexComment(ctx context.Context, comment entities.Comment) error
+	RemoveComment(ctx context.Context, id uint) error
+	// RemoveAuthor removes the posts and comments of a deleted account
+	RemoveAuthor(ctx context.Context, authorID uint) error
+}
+
+// NewIndex builds the index selected by driver, "mysql" (default) or "memory"
+func NewIndex(driver string, db *gorm.DB, timeout time.Duration) (Index, error) {
+	switch driver {
+	case "", "mysql":
+		return NewMySQLIndex(db, timeout), nil
+	case "memory":
+		return NewMemoryIndex(), nil
+	default:
+		return nil, fmt.Errorf("unknown search driver %q", driver)
+	}
+}
+
+// Tokenize splits text into lowercase words. Words of a single letter are dropped, they
+// match almost everything.
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) > 1 {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,92 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// snippetLength is the maximum length of a snippet in bytes, before escaping and marks
+const snippetLength = 200
+
+// Snippet cuts an excerpt of text around the first of terms it contains, HTML escapes it
+// and wraps every term in <mark>. Without a match the excerpt starts at the beginning.
+func Snippet(text string, terms []string) string {
+	match := map[string]bool{}
+	for _, t := range terms {
+		match[strings.ToLower(t)] = true
+	}
+	isMatch := func(w [2]int) bool { return match[strings.ToLower(text[w[0]:w[1]])] }
+	words := wordSpans(text)
+
+	// Start at a word boundary a little before the first match, for some context
+	from := 0
+	for i, w := range words {
+		if !isMatch(w) {
+			continue
+		}
+		if w[0] <= snippetLength/4 {
+			break
+		}
+		for _, before := range words[:i+1] {
+			if before[0] >= w[0]-snippetLength/4 {
+				from = before[0]
+				break
+			}
+		}
+		break
+	}
+
+	to := len(text)
+	if to-from > snippetLength {
+		to = from + snippetLength
+		for to > from && !utf8.RuneStart(text[to]) {
+			to--
+		}
+		// End at the last word that fits whole
+		for i := len(words) - 1; i >= 0; i-- {
+			if words[i][0] > from && words[i][1] <= to {
+				to = words[i][1]
+				break
+			}
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	last := from
+	for _, w := range words {
+		if w[0] >= from && w[1] <= to && isMatch(w) {
+			b.WriteString(html.EscapeString(text[last:w[0]]))
+			b.WriteString("<mark>" + html.EscapeString(text[w[0]:w[1]]) + "</mark>")
+			last = w[1]
+		}
+	}
+	b.WriteString(html.EscapeString(text[last:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// wordSpans returns the byte offsets of the words of text, split like Tokenize
+func wordSpans(text string) [][2]int {
+	var spans [][2]int
+	begin := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && begin < 0 {
+			begin = i
+		} else if !inWord && begin >= 0 {
+			spans = append(spans, [2]int{begin, i})
+			begin = -1
+		}
+	}
+	if begin >= 0 {
+		spans = append(spans, [2]int{begin, len(text)})
+	}
+	return spans
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(dir, "")
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "7/photo.png", []byte("png data"), "image/png"); err != nil {
+		t.Fatalf("LocalStorage.Put() error = %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "7", "photo.png")); err != nil || string(b) != "png data" {
+		t.Errorf("stored file = %q, %v", b, err)
+	}
+	if got := s.URL("7/photo.png"); got != "/uploads/7/photo.png" {
+		t.Errorf("LocalStorage.URL() = %q", got)
+	}
+
+	for _, key := range []string{"../escape.png", "/etc/passwd", ""} {
+		if err := s.Put(ctx, key, []byte("x"), "image/png"); err == nil {
+			t.Errorf("LocalStorage.Put(%q) error = nil, want an invalid key", key)
+		}
+	}
+
+	handler := http.StripPrefix("/uploads/", s.Handler())
+	for path, want := range map[string]int{"/uploads/7/photo.png": http.StatusOK, "/uploads/7/": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	if err := s.Delete(ctx, "7/photo.png"); err != nil {
+		t.Fatalf("LocalStorage.Delete() error = %v", err)
+	}
+	if err := s.Delete(ctx, "7/photo.png"); err != nil {
+		t.Errorf("LocalStorage.Delete() of a missing file error = %v", err)
+	}
+}
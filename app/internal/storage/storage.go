@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Storage keeps uploaded files. Keys are slash separated paths like "7/abc.png".
+//
+//go:generate mockery --name=Storage --output=mocks --outpkg=mocks
+type Storage interface {
+	// Put stores data under key, replacing what was there
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete removes the object under key, a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// URL is where clients download the object from
+	URL(key string) string
+}
+
+// Config selects and configures the Storage built by NewStorage
+type Config struct {
+	Driver string // "local" (default) or "s3"
+	// PublicURL is prepended to keys to make download URLs, it defaults to /uploads for the
+	// local driver and to the bucket URL for s3
+	PublicURL         string
+	LocalDir          string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+}
+
+func NewStorage(c Config) (Storage, error) {
+	switch c.Driver {
+	case "", "local":
+		return NewLocalStorage(c.LocalDir, c.PublicURL), nil
+	case "s3":
+		if c.S3Endpoint == "" || c.S3Bucket == "" {
+			return nil, fmt.Errorf("the s3 storage needs an endpoint and a bucket")
+		}
+		return NewS3Storage(c.S3Endpoint, c.S3Region, c.S3Bucket, c.S3AccessKeyID, c.S3SecretAccessKey, c.PublicURL), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", c.Driver)
+	}
+}
+
+// joinURL appends a key to a base URL
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	commentRepositories "app/internal/repositories/comment"
+	postRepositories "app/internal/repositories/post"
+	userRepositories "app/internal/repositories/user"
+	"app/internal/search"
+	"app/internal/storage"
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+// What happens to the posts and comments of a deleted account
+const (
+	// DeletionPolicyAnonymize keeps the content attributed to a deleted user
+	DeletionPolicyAnonymize = "anonymize"
+	// DeletionPolicyDelete removes the content together with the account
+	DeletionPolicyDelete = "delete"
+)
+
+// AccountUsecase is the self-service export and deletion of the current user's data
+type AccountUsecase interface {
+	Export(ctx context.Context) (entities.AccountExport, error)
+	Delete(ctx context.Context, req *entities.DeleteAccountRequest) error
+}
+
+type accountUsecase struct {
+	userRepo       userRepositories.UserRepository
+	postRepo       postRepositories.PostRepository
+	commentRepo    commentRepositories.CommentRepository
+	searchIndex    search.Index
+	storage        storage.Storage
+	hasher         commons.PasswordHasher
+	guard          LoginGuard
+	deletionPolicy string
+	contextTimeout time.Duration
+}
+
+func NewAccountUsecase(user userRepositories.UserRepository, post postRepositories.PostRepository, comment commentRepositories.CommentRepository, index search.Index, store storage.Storage, hasher commons.PasswordHasher, guard LoginGuard, deletionPolicy string, timeout time.Duration) AccountUsecase {
+	return &accountUsecase{
+		userRepo:       user,
+		postRepo:       post,
+		commentRepo:    comment,
+		searchIndex:    index,
+		storage:        store,
+		hasher:         hasher,
+		guard:          guard,
+		deletionPolicy: deletionPolicy,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *accountUsecase) Export(ctx context.Context) (entities.AccountExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return entities.AccountExport{}, commons.ErrUnauthorized
+	}
+
+	user, err := u.userRepo.FindByID(ctx, principal.UserID)
+	if err != nil {
+		return entities.AccountExport{}, err
+	}
+	posts, err := u.postRepo.GetAllPostsByAuthor(ctx, principal.UserID)
+	if err != nil {
+		return entities.AccountExport{}, err
+	}
+	comments, err := u.commentRepo.GetAllCommentsByAuthor(ctx, principal.UserID)
+	if err != nil {
+		return entities.AccountExport{}, err
+	}
+	if posts == nil {
+		posts = []entities.Post{}
+	}
+	if comments == nil {
+		comments = []entities.Comment{}
+	}
+
+	return entities.AccountExport{
+		Profile:    user,
+		Posts:      posts,
+		Comments:   comments,
+		ExportedAt: time.Now(),
+	}, nil
+}
+
+func (u *accountUsecase) Delete(ctx context.Context, req *entities.DeleteAccountRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return commons.ErrUnauthorized
+	}
+
+	user, err := u.userRepo.FindByID(ctx, principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	// A stolen access token alone must not be enough to delete an account with a password,
+	// and wrong passwords count against the same limits as failed logins so the token can
+	// not be used to guess it
+	if user.PasswordHash != "" {
+		if err := u.guard.Check(ctx, user.Email, req.IP); err != nil {
+			return err
+		}
+		match, _, err := u.hasher.Verify(req.Password, user.PasswordHash)
+		if err != nil {
+			return err
+		}
+		if !match {
+			if err := u.guard.Failed(ctx, user.Email, req.IP, &user.ID, "wrong_password_account_deletion"); err != nil {
+				return err
+			}
+			return commons.ErrInvalidCredentials
+		}
+	}
+
+	keepContent := u.deletionPolicy != DeletionPolicyDelete
+	keys, err := u.userRepo.DeleteAccount(ctx, user.ID, keepContent)
+	if err != nil {
+		return err
+	}
+	if !keepContent {
+		logIndexError(u.searchIndex.RemoveAuthor(ctx, user.ID))
+	}
+	// The account is gone already, a file that can not be deleted is only left behind
+	for _, key := range keys {
+		if err := u.storage.Delete(context.WithoutCancel(ctx), key); err != nil {
+			log.Printf("failed to delete upload %s of deleted account %d: %v", key, user.ID, err)
+		}
+	}
+	return nil
+}
+
+// WriteExportArchive writes the export as a zip archive with the data as JSON and every
+// post and comment as Markdown as well
+func WriteExportArchive(w io.Writer, export entities.AccountExport) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"posts.json", export.Posts},
+		{"comments.json", export.Comments},
+	}
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	for _, post := range export.Posts {
+		f, err := archive.Create(fmt.Sprintf("posts/%d.md", post.ID))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(f, "# %s\n\n_Published %s_\n\n%s\n", post.Title, post.CreatedAt.Format(time.RFC3339), post.Content); err != nil {
+			return err
+		}
+	}
+
+	var comments strings.Builder
+	comments.WriteString("# Comments\n")
+	for _, comment := range export.Comments {
+		fmt.Fprintf(&comments, "\n## On post %d, %s\n\n%s\n", comment.PostID, comment.CreatedAt.Format(time.RFC3339), comment.Content)
+	}
+	f, err := archive.Create("comments.md")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, comments.String()); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
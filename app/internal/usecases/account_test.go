@@ -0,0 +1,202 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	commentMocks "app/internal/repositories/comment/mocks"
+	"app/internal/repositories/loginattempt"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"app/internal/search"
+	storageMocks "app/internal/storage/mocks"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAccountUsecase_Delete(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockStorage := new(storageMocks.Storage)
+	timeout := time.Second * 2
+
+	hash, _ := testHasher.Hash("password")
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		policy  string
+		req     *entities.DeleteAccountRequest
+		wantErr error
+		mock    func()
+	}{
+		{
+			name:   "anonymize",
+			ctx:    ctx,
+			policy: DeletionPolicyAnonymize,
+			req:    &entities.DeleteAccountRequest{Password: "password"},
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, PasswordHash: hash}, nil)
+				mockRepo.On("DeleteAccount", mock.Anything, uint(1), true).Return([]string{"1/a.png", "1/b.png"}, nil)
+				mockStorage.On("Delete", mock.Anything, "1/a.png").Return(errors.New("unavailable"))
+				mockStorage.On("Delete", mock.Anything, "1/b.png").Return(nil)
+			},
+		},
+		{
+			name:   "delete content",
+			ctx:    ctx,
+			policy: DeletionPolicyDelete,
+			req:    &entities.DeleteAccountRequest{Password: "password"},
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, PasswordHash: hash}, nil)
+				mockRepo.On("DeleteAccount", mock.Anything, uint(1), false).Return([]string{"1/a.png"}, nil)
+				mockStorage.On("Delete", mock.Anything, "1/a.png").Return(nil)
+			},
+		},
+		{
+			name:    "wrong password",
+			ctx:     ctx,
+			policy:  DeletionPolicyAnonymize,
+			req:     &entities.DeleteAccountRequest{Password: "wrong-password"},
+			wantErr: commons.ErrInvalidCredentials,
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, PasswordHash: hash}, nil)
+			},
+		},
+		{
+			name:   "account without password",
+			ctx:    ctx,
+			policy: DeletionPolicyAnonymize,
+			req:    &entities.DeleteAccountRequest{},
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1}, nil)
+				mockRepo.On("DeleteAccount", mock.Anything, uint(1), true).Return(nil, nil)
+			},
+		},
+		{
+			name:    "unauthenticated",
+			ctx:     context.TODO(),
+			policy:  DeletionPolicyAnonymize,
+			req:     &entities.DeleteAccountRequest{},
+			wantErr: commons.ErrUnauthorized,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockStorage.ExpectedCalls = nil
+
+			tt.mock()
+			guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{MaxFailures: 5, MaxFailuresPerIP: 50, LockoutDuration: time.Minute})
+			u := NewAccountUsecase(mockRepo, nil, nil, search.NewMemoryIndex(), mockStorage, testHasher, guard, tt.policy, timeout)
+			if err := u.Delete(tt.ctx, tt.req); err != tt.wantErr {
+				t.Errorf("AccountUsecase.Delete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockRepo.AssertExpectations(t)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountUsecase_DeleteThrottled(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	hash, _ := testHasher.Hash("password")
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Email: "john@example.com", PasswordHash: hash}, nil)
+
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{
+		MaxFailures:      5,
+		MaxFailuresPerIP: 50,
+		LockoutDuration:  time.Minute,
+		BackoffBase:      time.Minute,
+		BackoffMax:       time.Minute,
+	})
+	u := NewAccountUsecase(mockRepo, nil, nil, search.NewMemoryIndex(), nil, testHasher, guard, DeletionPolicyAnonymize, time.Second*2)
+
+	if err := u.Delete(ctx, &entities.DeleteAccountRequest{Password: "guess", IP: "10.0.0.1"}); err != commons.ErrInvalidCredentials {
+		t.Fatalf("AccountUsecase.Delete() error = %v, wantErr %v", err, commons.ErrInvalidCredentials)
+	}
+	// The next guess has to wait out the backoff like a failed login
+	if err := u.Delete(ctx, &entities.DeleteAccountRequest{Password: "password", IP: "10.0.0.1"}); err != commons.ErrTooManyAttempts {
+		t.Errorf("AccountUsecase.Delete() error = %v, wantErr %v", err, commons.ErrTooManyAttempts)
+	}
+	mockRepo.AssertNotCalled(t, "DeleteAccount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountUsecase_Export(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockPostRepo := new(postMocks.PostRepository)
+	mockCommentRepo := new(commentMocks.CommentRepository)
+	timeout := time.Second * 2
+
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+	user := entities.User{ID: 1, Name: "John Doe", Email: "john@example.com"}
+	posts := []entities.Post{{ID: 3, Title: "Hello", Content: "World", AuthorID: 1}}
+
+	mockRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+	mockPostRepo.On("GetAllPostsByAuthor", mock.Anything, uint(1)).Return(posts, nil)
+	mockCommentRepo.On("GetAllCommentsByAuthor", mock.Anything, uint(1)).Return(nil, nil)
+
+	u := NewAccountUsecase(mockRepo, mockPostRepo, mockCommentRepo, search.NewMemoryIndex(), nil, testHasher, nil, DeletionPolicyAnonymize, timeout)
+	got, err := u.Export(ctx)
+	if err != nil {
+		t.Fatalf("AccountUsecase.Export() error = %v", err)
+	}
+	if got.Profile.ID != 1 || len(got.Posts) != 1 || got.Comments == nil || len(got.Comments) != 0 {
+		t.Errorf("AccountUsecase.Export() = %+v", got)
+	}
+	mockRepo.AssertExpectations(t)
+	mockPostRepo.AssertExpectations(t)
+	mockCommentRepo.AssertExpectations(t)
+}
+
+func TestWriteExportArchive(t *testing.T) {
+	export := entities.AccountExport{
+		Profile:  entities.User{ID: 1, Name: "John Doe", Email: "john@example.com", PasswordHash: "secret-hash"},
+		Posts:    []entities.Post{{ID: 3, Title: "Hello", Content: "World", AuthorID: 1}},
+		Comments: []entities.Comment{{ID: 7, PostID: 9, AuthorID: 1, Content: "Nice post"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteExportArchive(&buf, export); err != nil {
+		t.Fatalf("WriteExportArchive() error = %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+
+	contents := map[string]string{}
+	for _, f := range archive.File {
+		r, _ := f.Open()
+		b, _ := io.ReadAll(r)
+		r.Close()
+		contents[f.Name] = string(b)
+	}
+
+	want := map[string]string{
+		"profile.json":  "john@example.com",
+		"posts.json":    "Hello",
+		"comments.json": "Nice post",
+		"posts/3.md":    "# Hello",
+		"comments.md":   "## On post 9",
+	}
+	for name, substr := range want {
+		if !strings.Contains(contents[name], substr) {
+			t.Errorf("%s = %q, want it to contain %q", name, contents[name], substr)
+		}
+	}
+	if strings.Contains(contents["profile.json"], "secret-hash") {
+		t.Errorf("profile.json exposes the password hash")
+	}
+}
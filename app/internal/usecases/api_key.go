@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	apiKeyRepositories "app/internal/repositories/apikey"
+	userRepositories "app/internal/repositories/user"
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// apiKeyTouchInterval limits how often the last-used timestamp is written for a busy key
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyUsecase interface {
+	Create(ctx context.Context, req *entities.CreateAPIKeyRequest) (entities.CreatedAPIKey, error)
+	List(ctx context.Context) ([]entities.APIKey, error)
+	Revoke(ctx context.Context, id uint) error
+	ValidateAPIKey(ctx context.Context, key string) (commons.Principal, error)
+}
+
+type apiKeyUsecase struct {
+	apiKeyRepo     apiKeyRepositories.APIKeyRepository
+	userRepo       userRepositories.UserRepository
+	contextTimeout time.Duration
+}
+
+func NewAPIKeyUsecase(apiKey apiKeyRepositories.APIKeyRepository, user userRepositories.UserRepository, timeout time.Duration) APIKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepo:     apiKey,
+		userRepo:       user,
+		contextTimeout: timeout,
+	}
+}
+
+// mocked functions
+var generateAPIKey = func() (string, error) {
+	token, err := commons.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return commons.APIKeyPrefix + token, nil
+}
+
+func (u *apiKeyUsecase) Create(ctx context.Context, req *entities.CreateAPIKeyRequest) (entities.CreatedAPIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.CreatedAPIKey{}, err
+	}
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return entities.CreatedAPIKey{}, commons.ErrUnauthorized
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		return entities.CreatedAPIKey{}, err
+	}
+
+	apiKey := entities.APIKey{
+		UserID:  principal.UserID,
+		Name:    req.Name,
+		Prefix:  key[:len(commons.APIKeyPrefix)+6],
+		KeyHash: commons.HashToken(key),
+		Scopes:  strings.Join(req.Scopes, " "),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := u.apiKeyRepo.CreateAPIKey(ctx, &apiKey); err != nil {
+		return entities.CreatedAPIKey{}, err
+	}
+
+	return entities.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (u *apiKeyUsecase) List(ctx context.Context) ([]entities.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+
+	return u.apiKeyRepo.ListByUserID(ctx, principal.UserID)
+}
+
+func (u *apiKeyUsecase) Revoke(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return commons.ErrUnauthorized
+	}
+
+	return u.apiKeyRepo.Revoke(ctx, id, principal.UserID)
+}
+
+// ValidateAPIKey is used by the API key middleware to resolve the caller of a request
+func (u *apiKeyUsecase) ValidateAPIKey(ctx context.Context, key string) (commons.Principal, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if !strings.HasPrefix(key, commons.APIKeyPrefix) {
+		return commons.Principal{}, commons.ErrInvalidAPIKey
+	}
+
+	apiKey, err := u.apiKeyRepo.FindByHash(ctx, commons.HashToken(key))
+	if err != nil {
+		if err == commons.ErrNotFound {
+			return commons.Principal{}, commons.ErrInvalidAPIKey
+		}
+		return commons.Principal{}, err
+	}
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt)) {
+		return commons.Principal{}, commons.ErrInvalidAPIKey
+	}
+
+	// The user is loaded on every request, so role changes apply to existing keys right away
+	user, err := u.userRepo.FindByID(ctx, apiKey.UserID)
+	if err != nil {
+		if err == commons.ErrNotFound {
+			return commons.Principal{}, commons.ErrInvalidAPIKey
+		}
+		return commons.Principal{}, err
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err := u.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+			log.Printf("failed to update last use of api key %d: %v", apiKey.ID, err)
+		}
+	}
+
+	return commons.Principal{
+		UserID:        user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		APIKeyID:      apiKey.ID,
+		Scopes:        apiKey.Scopes,
+	}, nil
+}
@@ -0,0 +1,133 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	apiKeyMocks "app/internal/repositories/apikey/mocks"
+	"app/internal/repositories/user/mocks"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeyUsecase_Create(t *testing.T) {
+	mockRepo := new(apiKeyMocks.APIKeyRepository)
+	mockUserRepo := new(mocks.UserRepository)
+	timeout := time.Second * 2
+
+	generateAPIKey = func() (string, error) {
+		return "blog_some-api-key", nil
+	}
+
+	mockRepo.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(k *entities.APIKey) bool {
+		return k.UserID == 1 && k.KeyHash == commons.HashToken("blog_some-api-key") && k.Prefix == "blog_some-a" &&
+			k.Scopes == "posts:write comments:write" && k.ExpiresAt != nil
+	})).Return(nil)
+
+	u := NewAPIKeyUsecase(mockRepo, mockUserRepo, timeout)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+	got, err := u.Create(ctx, &entities.CreateAPIKeyRequest{Name: "publisher", Scopes: []string{"posts:write", "comments:write"}, ExpiresInDays: 30})
+	if err != nil {
+		t.Fatalf("APIKeyUsecase.Create() error = %v", err)
+	}
+	if got.Key != "blog_some-api-key" {
+		t.Errorf("APIKeyUsecase.Create() key = %v, want %v", got.Key, "blog_some-api-key")
+	}
+	mockRepo.AssertExpectations(t)
+
+	if _, err := u.Create(ctx, &entities.CreateAPIKeyRequest{Name: "admin", Scopes: []string{"users:manage"}}); err == nil {
+		t.Errorf("APIKeyUsecase.Create() accepted an unknown scope")
+	}
+}
+
+func TestAPIKeyUsecase_ValidateAPIKey(t *testing.T) {
+	mockRepo := new(apiKeyMocks.APIKeyRepository)
+	mockUserRepo := new(mocks.UserRepository)
+	timeout := time.Second * 2
+
+	hash := commons.HashToken("blog_some-api-key")
+	recently := time.Now().Add(-time.Second)
+	past := time.Now().Add(-time.Hour)
+	user := entities.User{ID: 1, Name: "John Doe", Email: "john@example.com", Role: commons.RoleUser, EmailVerified: true}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    commons.Principal
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success",
+			key:  "blog_some-api-key",
+			want: commons.Principal{UserID: 1, Name: "John Doe", Email: "john@example.com", Role: commons.RoleUser, EmailVerified: true, APIKeyID: 3, Scopes: "posts:write"},
+			mock: func() {
+				mockRepo.On("FindByHash", mock.Anything, hash).Return(entities.APIKey{ID: 3, UserID: 1, Scopes: "posts:write"}, nil)
+				mockUserRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+				mockRepo.On("TouchLastUsed", mock.Anything, uint(3), mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name: "recently used key is not touched again",
+			key:  "blog_some-api-key",
+			want: commons.Principal{UserID: 1, Name: "John Doe", Email: "john@example.com", Role: commons.RoleUser, EmailVerified: true, APIKeyID: 3, Scopes: "posts:write"},
+			mock: func() {
+				mockRepo.On("FindByHash", mock.Anything, hash).Return(entities.APIKey{ID: 3, UserID: 1, Scopes: "posts:write", LastUsedAt: &recently}, nil)
+				mockUserRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+			},
+		},
+		{
+			name:    "revoked key",
+			key:     "blog_some-api-key",
+			wantErr: commons.ErrInvalidAPIKey,
+			mock: func() {
+				mockRepo.On("FindByHash", mock.Anything, hash).Return(entities.APIKey{ID: 3, UserID: 1, RevokedAt: &past}, nil)
+			},
+		},
+		{
+			name:    "expired key",
+			key:     "blog_some-api-key",
+			wantErr: commons.ErrInvalidAPIKey,
+			mock: func() {
+				mockRepo.On("FindByHash", mock.Anything, hash).Return(entities.APIKey{ID: 3, UserID: 1, ExpiresAt: &past}, nil)
+			},
+		},
+		{
+			name:    "unknown key",
+			key:     "blog_some-api-key",
+			wantErr: commons.ErrInvalidAPIKey,
+			mock: func() {
+				mockRepo.On("FindByHash", mock.Anything, hash).Return(entities.APIKey{}, commons.ErrNotFound)
+			},
+		},
+		{
+			name:    "not an api key",
+			key:     "some-jwt-token",
+			wantErr: commons.ErrInvalidAPIKey,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockUserRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewAPIKeyUsecase(mockRepo, mockUserRepo, timeout)
+			got, err := u.ValidateAPIKey(context.TODO(), tt.key)
+			if err != tt.wantErr {
+				t.Errorf("APIKeyUsecase.ValidateAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("APIKeyUsecase.ValidateAPIKey() = %v, want %v", got, tt.want)
+			}
+			mockRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
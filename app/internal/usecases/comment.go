@@ -6,6 +6,7 @@ import (
 	commentRepositories "app/internal/repositories/comment"
 	postRepositories "app/internal/repositories/post"
 	userRepositories "app/internal/repositories/user"
+	"app/internal/search"
 	"context"
 	"time"
 
@@ -15,21 +16,28 @@ import (
 type CommentUsecase interface {
 	CreateComment(ctx context.Context, comment *entities.CreateCommentRequest) (*entities.Comment, error)
 	GetCommentsByPostID(ctx context.Context, postId uint, limit, offset int) ([]entities.Comment, error)
+	GetCommentAuthorID(ctx context.Context, postId, id uint) (uint, error)
+	DeleteComment(ctx context.Context, postId, id uint) error
+	BackfillContentHTML(ctx context.Context) (int, error)
 }
 
 type commentUsecase struct {
-	commentRepo    commentRepositories.CommentRepository
-	postRepo       postRepositories.PostRepository
-	userRepo       userRepositories.UserRepository
-	contextTimeout time.Duration
+	commentRepo          commentRepositories.CommentRepository
+	postRepo             postRepositories.PostRepository
+	userRepo             userRepositories.UserRepository
+	searchIndex          search.Index
+	requireVerifiedEmail bool
+	contextTimeout       time.Duration
 }
 
-func NewCommentUsecase(comment commentRepositories.CommentRepository, post postRepositories.PostRepository, user userRepositories.UserRepository, timeout time.Duration) CommentUsecase {
+func NewCommentUsecase(comment commentRepositories.CommentRepository, post postRepositories.PostRepository, user userRepositories.UserRepository, index search.Index, requireVerifiedEmail bool, timeout time.Duration) CommentUsecase {
 	return &commentUsecase{
-		commentRepo:    comment,
-		postRepo:       post,
-		userRepo:       user,
-		contextTimeout: timeout,
+		commentRepo:          comment,
+		postRepo:             post,
+		userRepo:             user,
+		searchIndex:          index,
+		requireVerifiedEmail: requireVerifiedEmail,
+		contextTimeout:       timeout,
 	}
 }
 
@@ -37,13 +45,17 @@ func (u *commentUsecase) CreateComment(ctx context.Context, req *entities.Create
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
-	// retrieve email from context
-	email := ctx.Value("user").(string)
-	user, err := u.userRepo.FindByEmail(ctx, email)
-	if err != nil {
-		return nil, err
+	// retrieve user logged in from context
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
 	}
-	req.AuthorID = user.ID
+	if u.requireVerifiedEmail {
+		if err := checkEmailVerified(ctx, u.userRepo, principal); err != nil {
+			return nil, err
+		}
+	}
+	req.AuthorID = principal.UserID
 
 	// Validate request
 	validator := validator.New()
@@ -52,8 +64,8 @@ func (u *commentUsecase) CreateComment(ctx context.Context, req *entities.Create
 	}
 
 	// Check if the post exists
-	_, err = u.postRepo.GetPostById(ctx, req.PostID)
-	if err != nil {
+	post, err := u.postRepo.GetPostById(ctx, req.PostID)
+	if err != nil || !isPublicPost(post) {
 		return nil, commons.ErrNotFound
 	}
 
@@ -63,13 +75,17 @@ func (u *commentUsecase) CreateComment(ctx context.Context, req *entities.Create
 		AuthorID: req.AuthorID,
 		PostID:   req.PostID,
 	}
+	if newComment.ContentHTML, err = commons.RenderMarkdown(req.Content); err != nil {
+		return nil, err
+	}
 
 	err = u.commentRepo.CreateComment(ctx, newComment)
 	if err != nil {
 		return nil, err
 	}
+	logIndexError(u.searchIndex.IndexComment(ctx, *newComment))
 
-	newComment.Author = user
+	newComment.Author = principal.User()
 
 	return newComment, nil
 }
@@ -82,9 +98,9 @@ func (u *commentUsecase) GetCommentsByPostID(ctx context.Context, postId uint, l
 		return nil, commons.ErrBadRequest
 	}
 
-	// Check if the post exists
-	_, err := u.postRepo.GetPostById(ctx, postId)
-	if err != nil {
+	// Check if the post exists, unpublished posts have no comments to show
+	post, err := u.postRepo.GetPostById(ctx, postId)
+	if err != nil || !isPublicPost(post) {
 		return nil, commons.ErrNotFound
 	}
 
@@ -95,3 +111,70 @@ func (u *commentUsecase) GetCommentsByPostID(ctx context.Context, postId uint, l
 
 	return u.commentRepo.GetCommentsByPostId(ctx, postId, limit, offset)
 }
+
+// GetCommentAuthorID returns who wrote a comment on the post, for authorizing requests about it
+func (u *commentUsecase) GetCommentAuthorID(ctx context.Context, postId, id uint) (uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	comment, err := u.commentRepo.GetCommentById(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if comment.PostID != postId {
+		return 0, commons.ErrNotFound
+	}
+	return comment.AuthorID, nil
+}
+
+func (u *commentUsecase) DeleteComment(ctx context.Context, postId, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	comment, err := u.commentRepo.GetCommentById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if comment.PostID != postId {
+		return commons.ErrNotFound
+	}
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return commons.ErrUnauthorized
+	}
+	// authors can remove their own comments, moderators and admins any comment
+	if !commons.IsOwnerOrPermitted(principal, comment.AuthorID, commons.PermDeleteAnyComment) {
+		return commons.ErrForbidden
+	}
+
+	if err := u.commentRepo.DeleteComment(ctx, id); err != nil {
+		return err
+	}
+	logIndexError(u.searchIndex.RemoveComment(ctx, id))
+	return nil
+}
+
+// BackfillContentHTML renders the content of the comments created before Markdown rendering
+func (u *commentUsecase) BackfillContentHTML(ctx context.Context) (int, error) {
+	filled := 0
+	for {
+		comments, err := u.commentRepo.GetCommentsWithoutContentHTML(ctx, contentHTMLBackfillBatchSize)
+		if err != nil {
+			return filled, err
+		}
+		if len(comments) == 0 {
+			return filled, nil
+		}
+		for _, comment := range comments {
+			html, err := commons.RenderMarkdown(comment.Content)
+			if err != nil {
+				return filled, err
+			}
+			if err := u.commentRepo.SetContentHTML(ctx, comment.ID, html); err != nil {
+				return filled, err
+			}
+			filled++
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"app/internal/mailer"
+	"app/internal/repositories/user/mocks"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEmailVerificationUsecase_VerifyEmail(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockJWTConfig := commons.ConfigJWT{
+		SecretJWT:       "secret",
+		ExpiresDuration: 1,
+	}
+	config := EmailVerificationConfig{VerifyURL: "http://localhost:8080/verify-email", ExpiresDuration: 1}
+	timeout := time.Second * 2
+
+	outbox := t.TempDir()
+	u := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(outbox, "noreply@example.com"), mockJWTConfig, config, timeout)
+
+	user := entities.User{ID: 1, Name: "John Doe", Email: "john@example.com"}
+	if err := u.SendVerification(context.TODO(), user); err != nil {
+		t.Fatalf("EmailVerificationUsecase.SendVerification() error = %v", err)
+	}
+
+	files, _ := os.ReadDir(outbox)
+	if len(files) != 1 {
+		t.Fatalf("EmailVerificationUsecase.SendVerification() sent %d mails, want 1", len(files))
+	}
+	body, _ := os.ReadFile(filepath.Join(outbox, files[0].Name()))
+	match := regexp.MustCompile(`/verify-email\?token=(\S+)`).FindSubmatch(body)
+	if match == nil {
+		t.Fatalf("EmailVerificationUsecase.SendVerification() mail does not contain a link:\n%s", body)
+	}
+	token, _ := url.QueryUnescape(string(match[1]))
+
+	accessToken, _ := mockJWTConfig.GenerateJWT(commons.Principal{UserID: 1, Email: "john@example.com", Role: commons.RoleUser, SessionID: 1})
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+		mock    func()
+	}{
+		{
+			name:  "success",
+			token: token,
+			mock: func() {
+				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(user, nil)
+				mockRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u entities.User) bool {
+					return u.ID == 1 && u.EmailVerified && u.EmailVerifiedAt != nil
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "tampered token",
+			token:   token + "x",
+			wantErr: commons.ErrInvalidVerifyToken,
+			mock:    func() {},
+		},
+		{
+			name:    "access token is not a verification token",
+			token:   accessToken,
+			wantErr: commons.ErrInvalidVerifyToken,
+			mock:    func() {},
+		},
+		{
+			name:    "missing token",
+			token:   "",
+			wantErr: commons.ErrBadRequest,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+
+			tt.mock()
+			if err := u.VerifyEmail(context.TODO(), tt.token); err != tt.wantErr {
+				t.Errorf("EmailVerificationUsecase.VerifyEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
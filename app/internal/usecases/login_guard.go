@@ -0,0 +1,142 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	loginAttemptRepositories "app/internal/repositories/loginattempt"
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// LoginGuard throttles password guessing per email and per IP
+type LoginGuard interface {
+	// Check rejects the attempt while the email or IP is backing off or locked
+	Check(ctx context.Context, email, ip string) error
+	Failed(ctx context.Context, email, ip string, userID *uint, reason string) error
+	Succeeded(ctx context.Context, email, ip string, userID uint) error
+	Unlock(ctx context.Context, email string) error
+}
+
+type LoginGuardConfig struct {
+	// MaxFailures locks the account after this many consecutive failures
+	MaxFailures int
+	// MaxFailuresPerIP blocks the IP after this many consecutive failures across accounts
+	MaxFailuresPerIP int
+	LockoutDuration  time.Duration
+	// BackoffBase is the wait after the first failure, it doubles with every further failure
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+type loginGuard struct {
+	repo   loginAttemptRepositories.LoginAttemptRepository
+	config LoginGuardConfig
+}
+
+func NewLoginGuard(repo loginAttemptRepositories.LoginAttemptRepository, config LoginGuardConfig) LoginGuard {
+	return &loginGuard{repo: repo, config: config}
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (g *loginGuard) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	for _, key := range []string{emailKey(email), ipKey(ip)} {
+		counter, err := g.repo.GetCounter(ctx, key)
+		if err != nil {
+			return err
+		}
+		if counter.LockedUntil != nil && now.Before(*counter.LockedUntil) {
+			if strings.HasPrefix(key, "email:") {
+				return commons.ErrAccountLocked
+			}
+			return commons.ErrTooManyAttempts
+		}
+		if g.isStale(counter, now) {
+			continue
+		}
+		if counter.Failures > 0 && now.Before(counter.LastFailureAt.Add(g.backoff(counter.Failures))) {
+			return commons.ErrTooManyAttempts
+		}
+	}
+	return nil
+}
+
+func (g *loginGuard) Failed(ctx context.Context, email, ip string, userID *uint, reason string) error {
+	now := time.Now()
+	limits := map[string]int{
+		emailKey(email): g.config.MaxFailures,
+		ipKey(ip):       g.config.MaxFailuresPerIP,
+	}
+	for key, limit := range limits {
+		counter, err := g.repo.GetCounter(ctx, key)
+		if err != nil {
+			return err
+		}
+		// Failures older than a lockout period no longer count towards the next lock
+		if counter.Failures > 0 && g.isStale(counter, now) {
+			if err := g.repo.ResetCounter(ctx, key); err != nil {
+				return err
+			}
+		}
+
+		counter, err = g.repo.IncrementFailures(ctx, key, now)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && counter.Failures >= limit {
+			if err := g.repo.LockUntil(ctx, key, now.Add(g.config.LockoutDuration)); err != nil {
+				return err
+			}
+		}
+	}
+
+	g.record(ctx, &entities.LoginAttempt{Email: email, IP: ip, UserID: userID, Success: false, Reason: reason})
+	return nil
+}
+
+func (g *loginGuard) Succeeded(ctx context.Context, email, ip string, userID uint) error {
+	// The IP counter is left alone, otherwise logging into an own account would reset
+	// the budget of an attacker guessing other accounts from the same address
+	if err := g.repo.ResetCounter(ctx, emailKey(email)); err != nil {
+		return err
+	}
+
+	g.record(ctx, &entities.LoginAttempt{Email: email, IP: ip, UserID: &userID, Success: true})
+	return nil
+}
+
+func (g *loginGuard) Unlock(ctx context.Context, email string) error {
+	return g.repo.ResetCounter(ctx, emailKey(email))
+}
+
+// backoff returns the wait required after the given number of consecutive failures
+func (g *loginGuard) backoff(failures int) time.Duration {
+	wait := g.config.BackoffBase
+	for i := 1; i < failures && wait < g.config.BackoffMax; i++ {
+		wait *= 2
+	}
+	if wait > g.config.BackoffMax {
+		wait = g.config.BackoffMax
+	}
+	return wait
+}
+
+func (g *loginGuard) isStale(counter entities.LoginCounter, now time.Time) bool {
+	return now.Sub(counter.LastFailureAt) > g.config.LockoutDuration
+}
+
+// record writes the audit entry, a failure here must not change the login outcome
+func (g *loginGuard) record(ctx context.Context, attempt *entities.LoginAttempt) {
+	if err := g.repo.RecordAttempt(ctx, attempt); err != nil {
+		log.Printf("failed to record login attempt: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/repositories/loginattempt"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLoginGuard(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  LoginGuardConfig
+		failed  []string
+		unlock  bool
+		email   string
+		wantErr error
+	}{
+		{
+			name:   "below the limit",
+			config: LoginGuardConfig{MaxFailures: 3, MaxFailuresPerIP: 10, LockoutDuration: time.Hour},
+			failed: []string{"john@example.com", "john@example.com"},
+		},
+		{
+			name:    "backing off after a failure",
+			config:  LoginGuardConfig{MaxFailures: 3, MaxFailuresPerIP: 10, LockoutDuration: time.Hour, BackoffBase: time.Hour, BackoffMax: time.Hour},
+			failed:  []string{"john@example.com"},
+			wantErr: commons.ErrTooManyAttempts,
+		},
+		{
+			name:    "account locked",
+			config:  LoginGuardConfig{MaxFailures: 3, MaxFailuresPerIP: 10, LockoutDuration: time.Hour},
+			failed:  []string{"john@example.com", "john@example.com", "john@example.com"},
+			wantErr: commons.ErrAccountLocked,
+		},
+		{
+			name:   "account unlocked",
+			config: LoginGuardConfig{MaxFailures: 3, MaxFailuresPerIP: 10, LockoutDuration: time.Hour},
+			failed: []string{"john@example.com", "john@example.com", "john@example.com"},
+			unlock: true,
+		},
+		{
+			name:   "lockout is per account",
+			config: LoginGuardConfig{MaxFailures: 3, MaxFailuresPerIP: 10, LockoutDuration: time.Hour},
+			failed: []string{"john@example.com", "john@example.com", "john@example.com"},
+			email:  "jane@example.com",
+		},
+		{
+			name:    "ip blocked",
+			config:  LoginGuardConfig{MaxFailures: 10, MaxFailuresPerIP: 3, LockoutDuration: time.Hour},
+			failed:  []string{"a@example.com", "b@example.com", "c@example.com"},
+			wantErr: commons.ErrTooManyAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), tt.config)
+			ctx := context.TODO()
+
+			for _, email := range tt.failed {
+				if err := g.Failed(ctx, email, "10.0.0.1", nil, "wrong_password"); err != nil {
+					t.Fatalf("LoginGuard.Failed() error = %v", err)
+				}
+			}
+			if tt.unlock {
+				if err := g.Unlock(ctx, "John@Example.com"); err != nil {
+					t.Fatalf("LoginGuard.Unlock() error = %v", err)
+				}
+			}
+
+			email := tt.email
+			if email == "" {
+				email = "john@example.com"
+			}
+			if err := g.Check(ctx, email, "10.0.0.1"); err != tt.wantErr {
+				t.Errorf("LoginGuard.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	mediaRepositories "app/internal/repositories/media"
+	"app/internal/storage"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"time"
+)
+
+// mediaTypes are the content types that can be uploaded, with the extension of their files
+var mediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type MediaConfig struct {
+	// MaxSize is the largest file that can be uploaded, in bytes
+	MaxSize int64
+	// UserQuota is how many bytes a user may have uploaded in total
+	UserQuota int64
+}
+
+type MediaUsecase interface {
+	Upload(ctx context.Context, req *entities.UploadMediaRequest) (*entities.Media, error)
+	GetMyMedia(ctx context.Context, limit, page int) ([]entities.Media, error)
+	GetMediaOwnerID(ctx context.Context, id uint) (uint, error)
+	DeleteMedia(ctx context.Context, id uint) error
+}
+
+type mediaUsecase struct {
+	mediaRepo      mediaRepositories.MediaRepository
+	storage        storage.Storage
+	config         MediaConfig
+	contextTimeout time.Duration
+}
+
+func NewMediaUsecase(media mediaRepositories.MediaRepository, store storage.Storage, config MediaConfig, timeout time.Duration) MediaUsecase {
+	return &mediaUsecase{
+		mediaRepo:      media,
+		storage:        store,
+		config:         config,
+		contextTimeout: timeout,
+	}
+}
+
+// Upload stores an image of the current user. The type is sniffed from the content, the
+// file name and the type the client claims are not trusted.
+func (u *mediaUsecase) Upload(ctx context.Context, req *entities.UploadMediaRequest) (*entities.Media, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+
+	size := int64(len(req.Data))
+	if size == 0 {
+		return nil, commons.ErrBadRequest
+	}
+	if size > u.config.MaxSize {
+		return nil, commons.ErrMediaTooLarge
+	}
+	contentType := http.DetectContentType(req.Data)
+	ext, ok := mediaTypes[contentType]
+	if !ok {
+		return nil, commons.ErrUnsupportedMediaType
+	}
+
+	// Only spares storing a file that can not fit, CreateMedia checks the quota again
+	used, err := u.mediaRepo.SumSizeByOwner(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if used+size > u.config.UserQuota {
+		return nil, commons.ErrMediaQuotaExceeded
+	}
+
+	token, err := commons.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%d/%s%s", principal.UserID, token, ext)
+	if err := u.storage.Put(ctx, key, req.Data, contentType); err != nil {
+		return nil, err
+	}
+
+	media := &entities.Media{
+		OwnerID:     principal.UserID,
+		Key:         key,
+		URL:         u.storage.URL(key),
+		Filename:    path.Base(req.Filename),
+		ContentType: contentType,
+		Size:        size,
+	}
+	if err := u.mediaRepo.CreateMedia(ctx, media, u.config.UserQuota); err != nil {
+		// Without its record the file could never be deleted
+		if err := u.storage.Delete(context.WithoutCancel(ctx), key); err != nil {
+			log.Printf("failed to delete orphaned upload %s: %v", key, err)
+		}
+		return nil, err
+	}
+	return media, nil
+}
+
+// GetMyMedia lists the files of the current user, latest first
+func (u *mediaUsecase) GetMyMedia(ctx context.Context, limit, page int) ([]entities.Media, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+	if page == 0 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	media, err := u.mediaRepo.GetMediaByOwner(ctx, principal.UserID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		media = []entities.Media{}
+	}
+	return media, nil
+}
+
+// GetMediaOwnerID returns who uploaded a file, for authorizing requests about it
+func (u *mediaUsecase) GetMediaOwnerID(ctx context.Context, id uint) (uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	media, err := u.mediaRepo.GetMediaByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return media.OwnerID, nil
+}
+
+// DeleteMedia deletes a file of the current user, or any file for those who may delete any
+// post. Covers have to be removed from their posts first, images used inline in the content
+// are not tracked and their links break.
+func (u *mediaUsecase) DeleteMedia(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return commons.ErrUnauthorized
+	}
+
+	media, err := u.mediaRepo.GetMediaByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !commons.IsOwnerOrPermitted(principal, media.OwnerID, commons.PermDeleteAnyPost) {
+		return commons.ErrForbidden
+	}
+
+	if err := u.mediaRepo.DeleteMedia(ctx, id); err != nil {
+		return err
+	}
+	return u.storage.Delete(ctx, media.Key)
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	mediaMocks "app/internal/repositories/media/mocks"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"app/internal/search"
+	storageMocks "app/internal/storage/mocks"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestMediaUsecase_Upload(t *testing.T) {
+	mockMediaRepo := new(mediaMocks.MediaRepository)
+	mockStorage := new(storageMocks.Storage)
+	timeout := time.Second * 2
+	config := MediaConfig{MaxSize: 32, UserQuota: 100}
+
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 7})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     *entities.UploadMediaRequest
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "png",
+			ctx:  ctx,
+			req:  &entities.UploadMediaRequest{Filename: "../cat.png", Data: testPNG},
+			mock: func() {
+				mockMediaRepo.On("SumSizeByOwner", mock.Anything, uint(7)).Return(int64(50), nil)
+				mockStorage.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, "7/") && strings.HasSuffix(key, ".png")
+				}), testPNG, "image/png").Return(nil)
+				mockStorage.On("URL", mock.Anything).Return("/uploads/7/abc.png")
+				mockMediaRepo.On("CreateMedia", mock.Anything, mock.MatchedBy(func(m *entities.Media) bool {
+					return m.OwnerID == 7 && m.Filename == "cat.png" && m.ContentType == "image/png" && m.Size == int64(len(testPNG))
+				}), int64(100)).Return(nil)
+			},
+		},
+		{
+			name:    "not an image",
+			ctx:     ctx,
+			req:     &entities.UploadMediaRequest{Filename: "cat.png", Data: []byte("<html><script>alert(1)</script>")},
+			wantErr: commons.ErrUnsupportedMediaType,
+			mock:    func() {},
+		},
+		{
+			name:    "too large",
+			ctx:     ctx,
+			req:     &entities.UploadMediaRequest{Filename: "cat.png", Data: append(testPNG, make([]byte, 32)...)},
+			wantErr: commons.ErrMediaTooLarge,
+			mock:    func() {},
+		},
+		{
+			name:    "over quota",
+			ctx:     ctx,
+			req:     &entities.UploadMediaRequest{Filename: "cat.png", Data: testPNG},
+			wantErr: commons.ErrMediaQuotaExceeded,
+			mock: func() {
+				mockMediaRepo.On("SumSizeByOwner", mock.Anything, uint(7)).Return(int64(90), nil)
+			},
+		},
+		{
+			name:    "over quota after a concurrent upload",
+			ctx:     ctx,
+			req:     &entities.UploadMediaRequest{Filename: "cat.png", Data: testPNG},
+			wantErr: commons.ErrMediaQuotaExceeded,
+			mock: func() {
+				mockMediaRepo.On("SumSizeByOwner", mock.Anything, uint(7)).Return(int64(50), nil)
+				mockStorage.On("Put", mock.Anything, mock.Anything, testPNG, "image/png").Return(nil)
+				mockStorage.On("URL", mock.Anything).Return("/uploads/7/abc.png")
+				mockMediaRepo.On("CreateMedia", mock.Anything, mock.Anything, int64(100)).Return(commons.ErrMediaQuotaExceeded)
+				mockStorage.On("Delete", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:    "database failure removes the file",
+			ctx:     ctx,
+			req:     &entities.UploadMediaRequest{Filename: "cat.png", Data: testPNG},
+			wantErr: commons.ErrTimeout,
+			mock: func() {
+				mockMediaRepo.On("SumSizeByOwner", mock.Anything, uint(7)).Return(int64(0), nil)
+				mockStorage.On("Put", mock.Anything, mock.Anything, testPNG, "image/png").Return(nil)
+				mockStorage.On("URL", mock.Anything).Return("/uploads/7/abc.png")
+				mockMediaRepo.On("CreateMedia", mock.Anything, mock.Anything, int64(100)).Return(commons.ErrTimeout)
+				mockStorage.On("Delete", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, "7/")
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "unauthenticated",
+			ctx:     context.TODO(),
+			req:     &entities.UploadMediaRequest{Filename: "cat.png", Data: testPNG},
+			wantErr: commons.ErrUnauthorized,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockMediaRepo.ExpectedCalls = nil
+			mockStorage.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewMediaUsecase(mockMediaRepo, mockStorage, config, timeout)
+			got, err := u.Upload(tt.ctx, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("MediaUsecase.Upload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.URL != "/uploads/7/abc.png" {
+				t.Errorf("MediaUsecase.Upload() url = %q", got.URL)
+			}
+			mockMediaRepo.AssertExpectations(t)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMediaUsecase_DeleteMedia(t *testing.T) {
+	mockMediaRepo := new(mediaMocks.MediaRepository)
+	mockStorage := new(storageMocks.Storage)
+	timeout := time.Second * 2
+
+	media := &entities.Media{ID: 3, OwnerID: 7, Key: "7/abc.png"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "owner",
+			ctx:  commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 7}),
+			mock: func() {
+				mockMediaRepo.On("GetMediaByID", mock.Anything, uint(3)).Return(media, nil)
+				mockMediaRepo.On("DeleteMedia", mock.Anything, uint(3)).Return(nil)
+				mockStorage.On("Delete", mock.Anything, "7/abc.png").Return(nil)
+			},
+		},
+		{
+			name:    "cover of a post",
+			ctx:     commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 7}),
+			wantErr: commons.ErrMediaInUse,
+			mock: func() {
+				mockMediaRepo.On("GetMediaByID", mock.Anything, uint(3)).Return(media, nil)
+				mockMediaRepo.On("DeleteMedia", mock.Anything, uint(3)).Return(commons.ErrMediaInUse)
+			},
+		},
+		{
+			name:    "someone else",
+			ctx:     commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 8}),
+			wantErr: commons.ErrForbidden,
+			mock: func() {
+				mockMediaRepo.On("GetMediaByID", mock.Anything, uint(3)).Return(media, nil)
+			},
+		},
+		{
+			name: "admin",
+			ctx:  commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 8, Role: commons.RoleAdmin}),
+			mock: func() {
+				mockMediaRepo.On("GetMediaByID", mock.Anything, uint(3)).Return(media, nil)
+				mockMediaRepo.On("DeleteMedia", mock.Anything, uint(3)).Return(nil)
+				mockStorage.On("Delete", mock.Anything, "7/abc.png").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockMediaRepo.ExpectedCalls = nil
+			mockStorage.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewMediaUsecase(mockMediaRepo, mockStorage, MediaConfig{}, timeout)
+			if err := u.DeleteMedia(tt.ctx, 3); err != tt.wantErr {
+				t.Errorf("MediaUsecase.DeleteMedia() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockMediaRepo.AssertExpectations(t)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_CreatePostCover(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	mockMediaRepo := new(mediaMocks.MediaRepository)
+	timeout := time.Second * 2
+
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 7})
+	coverID := uint(3)
+
+	tests := []struct {
+		name    string
+		media   *entities.Media
+		wantErr error
+	}{
+		{name: "own image", media: &entities.Media{ID: 3, OwnerID: 7, ContentType: "image/png"}},
+		{name: "image of someone else", media: &entities.Media{ID: 3, OwnerID: 8, ContentType: "image/png"}, wantErr: commons.ErrInvalidCover},
+		{name: "unknown media", wantErr: commons.ErrInvalidCover},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockMediaRepo.ExpectedCalls = nil
+
+			if tt.media != nil {
+				mockMediaRepo.On("GetMediaByID", mock.Anything, coverID).Return(tt.media, nil)
+			} else {
+				mockMediaRepo.On("GetMediaByID", mock.Anything, coverID).Return(nil, commons.ErrNotFound)
+			}
+			if tt.wantErr == nil {
+				mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "hello", uint(0)).Return(nil, nil)
+				mockPostRepo.On("CreatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(nil)
+			}
+
+			u := NewPostUsecase(mockPostRepo, nil, mockMediaRepo, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, err := u.CreatePost(ctx, &entities.CreatePostRequest{Title: "Hello", Content: "World", CoverID: &coverID})
+			if err != tt.wantErr {
+				t.Errorf("PostUsecase.CreatePost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (got.CoverID == nil || *got.CoverID != coverID || got.Cover == nil) {
+				t.Errorf("PostUsecase.CreatePost() cover = %v, want %d", got.CoverID, coverID)
+			}
+			mockPostRepo.AssertExpectations(t)
+			mockMediaRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"app/internal/oidc"
+	identityRepositories "app/internal/repositories/identity"
+	userRepositories "app/internal/repositories/user"
+	"context"
+	"crypto/subtle"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	oidcFlowPurpose = "oidc_login"
+	// oidcFlowDuration is how long the user may take to sign in at the provider
+	oidcFlowDuration = 10 * time.Minute
+)
+
+// OIDCUsecase signs users in through an OpenID Connect provider using the
+// authorization-code flow with PKCE
+type OIDCUsecase interface {
+	Start(ctx context.Context) (entities.OIDCStart, error)
+	Callback(ctx context.Context, req *entities.OIDCCallbackRequest) (entities.LoginResponse, error)
+}
+
+type oidcUsecase struct {
+	provider       oidc.Provider
+	identityRepo   identityRepositories.IdentityRepository
+	userRepo       userRepositories.UserRepository
+	users          UserUsecase
+	jwtConfig      commons.ConfigJWT
+	contextTimeout time.Duration
+}
+
+func NewOIDCUsecase(provider oidc.Provider, identity identityRepositories.IdentityRepository, user userRepositories.UserRepository, users UserUsecase, jwtConfig commons.ConfigJWT, timeout time.Duration) OIDCUsecase {
+	return &oidcUsecase{
+		provider:       provider,
+		identityRepo:   identity,
+		userRepo:       user,
+		users:          users,
+		jwtConfig:      jwtConfig,
+		contextTimeout: timeout,
+	}
+}
+
+// Start creates the state, nonce and PKCE verifier of a new sign-in. They are signed into
+// the flow token, which stays in the browser, so no server-side state is needed.
+func (u *oidcUsecase) Start(ctx context.Context) (entities.OIDCStart, error) {
+	state, err := commons.GenerateRandomToken(32)
+	if err != nil {
+		return entities.OIDCStart{}, err
+	}
+	nonce, err := commons.GenerateRandomToken(32)
+	if err != nil {
+		return entities.OIDCStart{}, err
+	}
+	verifier := oidc.GenerateVerifier()
+
+	flowToken, err := u.jwtConfig.GeneratePurposeJWTWithClaims(oidcFlowPurpose, state, map[string]string{
+		"nonce":    nonce,
+		"verifier": verifier,
+	}, oidcFlowDuration)
+	if err != nil {
+		return entities.OIDCStart{}, err
+	}
+
+	return entities.OIDCStart{
+		AuthURL:   u.provider.AuthCodeURL(state, nonce, verifier),
+		FlowToken: flowToken,
+	}, nil
+}
+
+func (u *oidcUsecase) Callback(ctx context.Context, req *entities.OIDCCallbackRequest) (entities.LoginResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.LoginResponse{}, err
+	}
+
+	// The state must match the flow started in the same browser, which stops an attacker
+	// from completing their own sign-in in the victim's browser
+	claims, err := u.jwtConfig.ParsePurposeJWTClaims(oidcFlowPurpose, req.FlowToken)
+	if err != nil {
+		return entities.LoginResponse{}, commons.ErrInvalidOIDCState
+	}
+	state, _ := claims["sub"].(string)
+	if subtle.ConstantTimeCompare([]byte(state), []byte(req.State)) != 1 {
+		return entities.LoginResponse{}, commons.ErrInvalidOIDCState
+	}
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+
+	identity, err := u.provider.Exchange(ctx, req.Code, verifier, nonce)
+	if err != nil {
+		log.Printf("oidc sign-in failed: %v", err)
+		return entities.LoginResponse{}, commons.ErrOIDCLoginFailed
+	}
+
+	user, err := u.resolveUser(ctx, identity)
+	if err != nil {
+		return entities.LoginResponse{}, err
+	}
+
+	return u.users.LoginExternal(ctx, user)
+}
+
+// resolveUser returns the local user of the provider account. The first sign-in links an
+// existing account with the same verified email, or creates a new account. An existing
+// account whose email was never verified loses its password, sessions and API keys on
+// linking: whoever registered it did not prove to own the email, and may be waiting for the
+// owner to sign in to share the account.
+func (u *oidcUsecase) resolveUser(ctx context.Context, identity oidc.Identity) (entities.User, error) {
+	link, err := u.identityRepo.FindByProviderSubject(ctx, identity.Issuer, identity.Subject)
+	if err == nil {
+		return u.userRepo.FindByID(ctx, link.UserID)
+	}
+	if err != commons.ErrNotFound {
+		return entities.User{}, err
+	}
+
+	if identity.Email == "" {
+		log.Printf("oidc sign-in of %s failed: no email claim", identity.Subject)
+		return entities.User{}, commons.ErrOIDCLoginFailed
+	}
+
+	user, err := u.userRepo.FindByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		// Linking on an unverified email would let anyone who can register that address
+		// at the provider take over the local account
+		if !identity.EmailVerified {
+			return entities.User{}, commons.ErrOIDCAccountConflict
+		}
+		if !user.EmailVerified {
+			if err := u.userRepo.ResetCredentials(ctx, user.ID); err != nil {
+				return entities.User{}, err
+			}
+			if user, err = u.userRepo.FindByID(ctx, user.ID); err != nil {
+				return entities.User{}, err
+			}
+		}
+	case err == commons.ErrNotFound:
+		user, err = u.createUser(ctx, identity)
+		if err != nil {
+			return entities.User{}, err
+		}
+	default:
+		return entities.User{}, err
+	}
+
+	err = u.identityRepo.CreateIdentity(ctx, &entities.UserIdentity{
+		UserID:   user.ID,
+		Provider: identity.Issuer,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+	return user, nil
+}
+
+// createUser creates an account without a password, one can be set through the password reset
+func (u *oidcUsecase) createUser(ctx context.Context, identity oidc.Identity) (entities.User, error) {
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+	user := entities.User{
+		Name:          name,
+		Email:         identity.Email,
+		Role:          commons.RoleUser,
+		EmailVerified: identity.EmailVerified,
+	}
+	if identity.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := u.userRepo.CreateUser(ctx, user); err != nil {
+		return entities.User{}, err
+	}
+	// CreateUser does not hand back the generated ID
+	return u.userRepo.FindByEmail(ctx, identity.Email)
+}
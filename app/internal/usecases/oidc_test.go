@@ -0,0 +1,172 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"app/internal/mailer"
+	"app/internal/oidc"
+	oidcMocks "app/internal/oidc/mocks"
+	identityMocks "app/internal/repositories/identity/mocks"
+	"app/internal/repositories/loginattempt"
+	recoveryCodeMocks "app/internal/repositories/recoverycode/mocks"
+	sessionMocks "app/internal/repositories/session/mocks"
+	"app/internal/repositories/user/mocks"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOIDCUsecase_Callback(t *testing.T) {
+	mockProvider := new(oidcMocks.Provider)
+	mockIdentityRepo := new(identityMocks.IdentityRepository)
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	mockJWTConfig := commons.ConfigJWT{
+		SecretJWT:              "secret",
+		ExpiresDuration:        1,
+		RefreshExpiresDuration: 1,
+	}
+	timeout := time.Second * 2
+	verification := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), mockJWTConfig, EmailVerificationConfig{ExpiresDuration: 1}, timeout)
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{})
+	twoFactor := NewTwoFactorUsecase(mockRepo, new(recoveryCodeMocks.RecoveryCodeRepository), nil, mockJWTConfig, TwoFactorConfig{ChallengeDuration: time.Minute}, timeout)
+	users := NewUserUsecase(mockRepo, mockSessionRepo, verification, guard, twoFactor, testHasher, mockJWTConfig, timeout)
+
+	generateJWT = func(u *userUsecase, user entities.User, sessionID uint) (string, error) {
+		return "some-jwt-token", nil
+	}
+	generateRefreshToken = func() (string, error) {
+		return "some-refresh-token", nil
+	}
+
+	issuer := "https://idp.example.com"
+	existing := entities.User{ID: 1, Name: "John Doe", Email: "john@example.com", Role: commons.RoleUser, EmailVerified: true}
+	preRegistered := entities.User{ID: 1, Name: "John Doe", Email: "john@example.com", Role: commons.RoleUser, PasswordHash: "hash-of-someone-else"}
+	verified := oidc.Identity{Issuer: issuer, Subject: "abc", Email: "john@example.com", EmailVerified: true, Name: "John Doe"}
+	unverified := oidc.Identity{Issuer: issuer, Subject: "abc", Email: "john@example.com"}
+
+	tests := []struct {
+		name         string
+		wrongState   bool
+		identity     oidc.Identity
+		wantErr      error
+		wantSession  bool
+		mock         func()
+		mockExchange bool
+	}{
+		{
+			name:         "linked account",
+			identity:     verified,
+			wantSession:  true,
+			mockExchange: true,
+			mock: func() {
+				mockIdentityRepo.On("FindByProviderSubject", mock.Anything, issuer, "abc").Return(entities.UserIdentity{UserID: 1}, nil)
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(existing, nil)
+			},
+		},
+		{
+			name:         "first sign-in links the account with the same verified email",
+			identity:     verified,
+			wantSession:  true,
+			mockExchange: true,
+			mock: func() {
+				mockIdentityRepo.On("FindByProviderSubject", mock.Anything, issuer, "abc").Return(entities.UserIdentity{}, commons.ErrNotFound)
+				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(existing, nil)
+				mockIdentityRepo.On("CreateIdentity", mock.Anything, mock.MatchedBy(func(i *entities.UserIdentity) bool {
+					return i.UserID == 1 && i.Provider == issuer && i.Subject == "abc"
+				})).Return(nil)
+			},
+		},
+		{
+			name:         "first sign-in takes over an account with an unverified email",
+			identity:     verified,
+			wantSession:  true,
+			mockExchange: true,
+			mock: func() {
+				mockIdentityRepo.On("FindByProviderSubject", mock.Anything, issuer, "abc").Return(entities.UserIdentity{}, commons.ErrNotFound)
+				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(preRegistered, nil)
+				mockRepo.On("ResetCredentials", mock.Anything, uint(1)).Return(nil)
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(existing, nil)
+				mockIdentityRepo.On("CreateIdentity", mock.Anything, mock.MatchedBy(func(i *entities.UserIdentity) bool {
+					return i.UserID == 1
+				})).Return(nil)
+			},
+		},
+		{
+			name:         "first sign-in creates an account",
+			identity:     verified,
+			wantSession:  true,
+			mockExchange: true,
+			mock: func() {
+				mockIdentityRepo.On("FindByProviderSubject", mock.Anything, issuer, "abc").Return(entities.UserIdentity{}, commons.ErrNotFound)
+				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(entities.User{}, commons.ErrNotFound).Once()
+				mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u entities.User) bool {
+					return u.Email == "john@example.com" && u.EmailVerified && u.PasswordHash == "" && u.Role == commons.RoleUser
+				})).Return(nil)
+				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(existing, nil).Once()
+				mockIdentityRepo.On("CreateIdentity", mock.Anything, mock.AnythingOfType("*entities.UserIdentity")).Return(nil)
+			},
+		},
+		{
+			name:         "unverified email of an existing account",
+			identity:     unverified,
+			wantErr:      commons.ErrOIDCAccountConflict,
+			mockExchange: true,
+			mock: func() {
+				mockIdentityRepo.On("FindByProviderSubject", mock.Anything, issuer, "abc").Return(entities.UserIdentity{}, commons.ErrNotFound)
+				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(existing, nil)
+			},
+		},
+		{
+			name:       "state of another flow",
+			wrongState: true,
+			wantErr:    commons.ErrInvalidOIDCState,
+			mock:       func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProvider.ExpectedCalls = nil
+			mockIdentityRepo.ExpectedCalls = nil
+			mockRepo.ExpectedCalls = nil
+			mockSessionRepo.ExpectedCalls = nil
+
+			u := NewOIDCUsecase(mockProvider, mockIdentityRepo, mockRepo, users, mockJWTConfig, timeout)
+
+			mockProvider.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything).Return(issuer + "/authorize")
+			start, err := u.Start(context.TODO())
+			if err != nil {
+				t.Fatalf("OIDCUsecase.Start() error = %v", err)
+			}
+			claims, _ := mockJWTConfig.ParsePurposeJWTClaims(oidcFlowPurpose, start.FlowToken)
+			state := claims["sub"].(string)
+			if tt.wrongState {
+				state = "another-state"
+			}
+
+			tt.mock()
+			if tt.mockExchange {
+				mockProvider.On("Exchange", mock.Anything, "some-code", claims["verifier"], claims["nonce"]).Return(tt.identity, nil)
+			}
+			if tt.wantSession {
+				mockSessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*entities.Session")).Return(nil)
+			}
+
+			got, err := u.Callback(context.TODO(), &entities.OIDCCallbackRequest{Code: "some-code", State: state, FlowToken: start.FlowToken})
+			if err != tt.wantErr {
+				t.Errorf("OIDCUsecase.Callback() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantSession && (got.TokenResponse == nil || got.AccessToken != "some-jwt-token") {
+				t.Errorf("OIDCUsecase.Callback() = %+v, want a token pair", got)
+			}
+			mockProvider.AssertExpectations(t)
+			mockIdentityRepo.AssertExpectations(t)
+			mockRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
+		})
+	}
+}
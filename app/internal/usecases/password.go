@@ -0,0 +1,140 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"app/internal/mailer"
+	passwordResetRepositories "app/internal/repositories/passwordreset"
+	sessionRepositories "app/internal/repositories/session"
+	userRepositories "app/internal/repositories/user"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type PasswordUsecase interface {
+	ForgotPassword(ctx context.Context, req *entities.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *entities.ResetPasswordRequest) error
+}
+
+type PasswordResetConfig struct {
+	// ResetURL is the page of the frontend handling the reset, the token is appended as ?token=
+	ResetURL string
+	// ExpiresDuration is the reset token lifetime in minutes
+	ExpiresDuration int
+}
+
+type passwordUsecase struct {
+	userRepo       userRepositories.UserRepository
+	sessionRepo    sessionRepositories.SessionRepository
+	resetRepo      passwordResetRepositories.PasswordResetRepository
+	hasher         commons.PasswordHasher
+	mailer         mailer.Mailer
+	config         PasswordResetConfig
+	contextTimeout time.Duration
+}
+
+func NewPasswordUsecase(user userRepositories.UserRepository, session sessionRepositories.SessionRepository, reset passwordResetRepositories.PasswordResetRepository, hasher commons.PasswordHasher, m mailer.Mailer, config PasswordResetConfig, timeout time.Duration) PasswordUsecase {
+	return &passwordUsecase{
+		userRepo:       user,
+		sessionRepo:    session,
+		resetRepo:      reset,
+		hasher:         hasher,
+		mailer:         m,
+		config:         config,
+		contextTimeout: timeout,
+	}
+}
+
+var generateResetToken = func() (string, error) {
+	return commons.GenerateRandomToken(32)
+}
+
+func (u *passwordUsecase) ForgotPassword(ctx context.Context, req *entities.ForgotPasswordRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return err
+	}
+
+	// Unknown emails are answered the same way, so the endpoint can not be used to enumerate accounts
+	user, err := u.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == commons.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return err
+	}
+	reset := &entities.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: commons.HashToken(token),
+		ExpiresAt: time.Now().Add(time.Minute * time.Duration(u.config.ExpiresDuration)),
+	}
+	if err := u.resetRepo.CreatePasswordReset(ctx, reset); err != nil {
+		return err
+	}
+
+	msg := mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s?token=%s\n\nThe link expires in %d minutes and can only be used once. If you did not request a reset, you can ignore this email.\n",
+			user.Name, u.config.ResetURL, token, u.config.ExpiresDuration),
+	}
+	// A failed send is answered like an unknown email too, an error would reveal the account
+	if err := u.mailer.Send(ctx, msg); err != nil {
+		log.Printf("failed to send password reset email: %v", err)
+	}
+
+	return nil
+}
+
+func (u *passwordUsecase) ResetPassword(ctx context.Context, req *entities.ResetPasswordRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return err
+	}
+
+	reset, err := u.resetRepo.FindByTokenHash(ctx, commons.HashToken(req.Token))
+	if err != nil {
+		if err == commons.ErrNotFound {
+			return commons.ErrInvalidResetToken
+		}
+		return err
+	}
+	if reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return commons.ErrInvalidResetToken
+	}
+
+	user, err := u.userRepo.FindByID(ctx, reset.UserID)
+	if err != nil {
+		return commons.ErrInvalidResetToken
+	}
+
+	if err := u.resetRepo.MarkUsed(ctx, reset.ID); err != nil {
+		return err
+	}
+
+	user.PasswordHash, err = u.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := u.userRepo.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+
+	// Whoever held the old password should not keep access through existing sessions
+	return u.sessionRepo.RevokeAllByUserID(ctx, user.ID)
+}
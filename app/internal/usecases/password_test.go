@@ -0,0 +1,174 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"app/internal/mailer"
+	mailerMocks "app/internal/mailer/mocks"
+	passwordResetMocks "app/internal/repositories/passwordreset/mocks"
+	sessionMocks "app/internal/repositories/session/mocks"
+	"app/internal/repositories/user/mocks"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPasswordUsecase_ForgotPassword(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	mockResetRepo := new(passwordResetMocks.PasswordResetRepository)
+	config := PasswordResetConfig{ResetURL: "http://localhost:3000/reset-password", ExpiresDuration: 30}
+	timeout := time.Second * 2
+
+	generateResetToken = func() (string, error) {
+		return "some-reset-token", nil
+	}
+
+	tests := []struct {
+		name      string
+		email     string
+		wantMails int
+		mock      func()
+	}{
+		{
+			name:      "registered email receives a link",
+			email:     "john@example.com",
+			wantMails: 1,
+			mock: func() {
+				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(entities.User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+				mockResetRepo.On("CreatePasswordReset", mock.Anything, mock.MatchedBy(func(r *entities.PasswordReset) bool {
+					return r.UserID == 1 && r.TokenHash == commons.HashToken("some-reset-token")
+				})).Return(nil)
+			},
+		},
+		{
+			name:      "unknown email is not revealed",
+			email:     "jane@example.com",
+			wantMails: 0,
+			mock: func() {
+				mockRepo.On("FindByEmail", mock.Anything, "jane@example.com").Return(entities.User{}, commons.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockResetRepo.ExpectedCalls = nil
+
+			tt.mock()
+			outbox := t.TempDir()
+			u := NewPasswordUsecase(mockRepo, mockSessionRepo, mockResetRepo, testHasher, mailer.NewOutboxMailer(outbox, "noreply@example.com"), config, timeout)
+			if err := u.ForgotPassword(context.TODO(), &entities.ForgotPasswordRequest{Email: tt.email}); err != nil {
+				t.Errorf("PasswordUsecase.ForgotPassword() error = %v", err)
+				return
+			}
+
+			files, _ := os.ReadDir(outbox)
+			if len(files) != tt.wantMails {
+				t.Fatalf("PasswordUsecase.ForgotPassword() sent %d mails, want %d", len(files), tt.wantMails)
+			}
+			if tt.wantMails > 0 {
+				body, _ := os.ReadFile(filepath.Join(outbox, files[0].Name()))
+				if !strings.Contains(string(body), "http://localhost:3000/reset-password?token=some-reset-token") {
+					t.Errorf("PasswordUsecase.ForgotPassword() mail does not contain the reset link:\n%s", body)
+				}
+			}
+			mockRepo.AssertExpectations(t)
+			mockResetRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPasswordUsecase_ForgotPasswordMailerFailure(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockResetRepo := new(passwordResetMocks.PasswordResetRepository)
+	mockMailer := new(mailerMocks.Mailer)
+	config := PasswordResetConfig{ResetURL: "http://localhost:3000/reset-password", ExpiresDuration: 30}
+
+	mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(entities.User{ID: 1, Email: "john@example.com"}, nil)
+	mockResetRepo.On("CreatePasswordReset", mock.Anything, mock.Anything).Return(nil)
+	mockMailer.On("Send", mock.Anything, mock.Anything).Return(errors.New("smtp unavailable"))
+
+	u := NewPasswordUsecase(mockRepo, new(sessionMocks.SessionRepository), mockResetRepo, testHasher, mockMailer, config, time.Second*2)
+	if err := u.ForgotPassword(context.TODO(), &entities.ForgotPasswordRequest{Email: "john@example.com"}); err != nil {
+		t.Errorf("PasswordUsecase.ForgotPassword() error = %v, want the same answer as for an unknown email", err)
+	}
+	mockMailer.AssertExpectations(t)
+}
+
+func TestPasswordUsecase_ResetPassword(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	mockResetRepo := new(passwordResetMocks.PasswordResetRepository)
+	config := PasswordResetConfig{ExpiresDuration: 30}
+	timeout := time.Second * 2
+
+	tokenHash := commons.HashToken("some-reset-token")
+	usedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mockResetRepo.On("FindByTokenHash", mock.Anything, tokenHash).Return(entities.PasswordReset{ID: 3, UserID: 1, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Email: "john@example.com"}, nil)
+				mockResetRepo.On("MarkUsed", mock.Anything, uint(3)).Return(nil)
+				mockRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u entities.User) bool {
+					ok, _, _ := testHasher.Verify("new-password", u.PasswordHash)
+					return u.ID == 1 && ok
+				})).Return(nil)
+				mockSessionRepo.On("RevokeAllByUserID", mock.Anything, uint(1)).Return(nil)
+			},
+		},
+		{
+			name:    "unknown token",
+			wantErr: commons.ErrInvalidResetToken,
+			mock: func() {
+				mockResetRepo.On("FindByTokenHash", mock.Anything, tokenHash).Return(entities.PasswordReset{}, commons.ErrNotFound)
+			},
+		},
+		{
+			name:    "token already used",
+			wantErr: commons.ErrInvalidResetToken,
+			mock: func() {
+				mockResetRepo.On("FindByTokenHash", mock.Anything, tokenHash).Return(entities.PasswordReset{ID: 3, UserID: 1, ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt}, nil)
+			},
+		},
+		{
+			name:    "token expired",
+			wantErr: commons.ErrInvalidResetToken,
+			mock: func() {
+				mockResetRepo.On("FindByTokenHash", mock.Anything, tokenHash).Return(entities.PasswordReset{ID: 3, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockSessionRepo.ExpectedCalls = nil
+			mockResetRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewPasswordUsecase(mockRepo, mockSessionRepo, mockResetRepo, testHasher, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), config, timeout)
+			err := u.ResetPassword(context.TODO(), &entities.ResetPasswordRequest{Token: "some-reset-token", NewPassword: "new-password"})
+			if err != tt.wantErr {
+				t.Errorf("PasswordUsecase.ResetPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
+			mockResetRepo.AssertExpectations(t)
+		})
+	}
+}
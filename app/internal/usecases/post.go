@@ -3,33 +3,71 @@ package usecases
 import (
 	"app/internal/commons"
 	"app/internal/entities"
+	categoryRepositories "app/internal/repositories/category"
+	mediaRepositories "app/internal/repositories/media"
 	postRepositories "app/internal/repositories/post"
 	userRepositories "app/internal/repositories/user"
+	"app/internal/search"
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
 )
 
+// slugBackfillBatchSize is how many posts without a slug are loaded at once
+const slugBackfillBatchSize = 100
+
+// contentHTMLBackfillBatchSize is how many posts or comments without rendered content are
+// loaded at once
+const contentHTMLBackfillBatchSize = 100
+
 type PostUsecase interface {
 	CreatePost(ctx context.Context, post *entities.CreatePostRequest) (*entities.Post, error)
-	GetAllPosts(ctx context.Context, limit, offset int) ([]entities.Post, error)
+	GetAllPosts(ctx context.Context, tag, category string, limit, page int) ([]entities.Post, error)
 	GetPostByID(ctx context.Context, id uint) (*entities.Post, error)
+	GetPostAuthorID(ctx context.Context, id uint) (uint, error)
+	GetTrashedPostAuthorID(ctx context.Context, id uint) (uint, error)
 	UpdatePost(ctx context.Context, post *entities.UpdatePostRequest) (*entities.Post, error)
+	PatchPost(ctx context.Context, id, version uint, patch []byte) (*entities.Post, error)
 	DeletePost(ctx context.Context, id uint) error
+	PublishPost(ctx context.Context, id uint) (*entities.Post, error)
+	UnpublishPost(ctx context.Context, id uint) (*entities.Post, error)
+	ArchivePost(ctx context.Context, id uint) (*entities.Post, error)
+	SchedulePost(ctx context.Context, req *entities.SchedulePostRequest) (*entities.Post, error)
+	GetMyPosts(ctx context.Context, status string, limit, page int) ([]entities.Post, error)
+	GetPostBySlug(ctx context.Context, slug string) (post *entities.Post, moved bool, err error)
+	BackfillSlugs(ctx context.Context) (int, error)
+	GetRevisions(ctx context.Context, postID uint) ([]entities.PostRevision, error)
+	DiffRevisions(ctx context.Context, postID, from, to uint) (*entities.RevisionDiff, error)
+	RestoreRevision(ctx context.Context, postID, number uint) (*entities.Post, error)
+	RestorePost(ctx context.Context, id uint) (*entities.Post, error)
+	GetMyTrash(ctx context.Context, limit, page int) ([]entities.Post, error)
+	RenderPreview(ctx context.Context, req *entities.RenderPreviewRequest) (*entities.RenderPreview, error)
+	BackfillContentHTML(ctx context.Context) (int, error)
 }
 
 type postUsecase struct {
-	postRepo       postRepositories.PostRepository
-	userRepo       userRepositories.UserRepository
-	contextTimeout time.Duration
+	postRepo             postRepositories.PostRepository
+	categoryRepo         categoryRepositories.CategoryRepository
+	mediaRepo            mediaRepositories.MediaRepository
+	userRepo             userRepositories.UserRepository
+	searchIndex          search.Index
+	requireVerifiedEmail bool
+	contextTimeout       time.Duration
 }
 
-func NewPostUsecase(post postRepositories.PostRepository, user userRepositories.UserRepository, timeout time.Duration) PostUsecase {
+func NewPostUsecase(post postRepositories.PostRepository, category categoryRepositories.CategoryRepository, media mediaRepositories.MediaRepository, user userRepositories.UserRepository, index search.Index, requireVerifiedEmail bool, timeout time.Duration) PostUsecase {
 	return &postUsecase{
-		postRepo:       post,
-		userRepo:       user,
-		contextTimeout: timeout,
+		postRepo:             post,
+		categoryRepo:         category,
+		mediaRepo:            media,
+		userRepo:             user,
+		searchIndex:          index,
+		requireVerifiedEmail: requireVerifiedEmail,
+		contextTimeout:       timeout,
 	}
 }
 
@@ -38,12 +76,16 @@ func (u *postUsecase) CreatePost(ctx context.Context, req *entities.CreatePostRe
 	defer cancel()
 
 	// retrieve user logged in from context
-	email := ctx.Value("user").(string)
-	user, err := u.userRepo.FindByEmail(ctx, email)
-	if err != nil {
-		return nil, err
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+	if u.requireVerifiedEmail {
+		if err := checkEmailVerified(ctx, u.userRepo, principal); err != nil {
+			return nil, err
+		}
 	}
-	req.AuthorID = user.ID
+	req.AuthorID = principal.UserID
 
 	validator := validator.New()
 	if err := validator.Struct(req); err != nil {
@@ -54,19 +96,59 @@ func (u *postUsecase) CreatePost(ctx context.Context, req *entities.CreatePostRe
 		Title:    req.Title,
 		Content:  req.Content,
 		AuthorID: req.AuthorID,
+		Status:   entities.PostStatusDraft,
+	}
+	// Posts were public right away before drafts existed, so that stays the default
+	if req.Status != entities.PostStatusDraft && req.PublishAt == nil {
+		now := time.Now()
+		newPost.Status = entities.PostStatusPublished
+		newPost.PublishedAt = &now
+	}
+	if req.PublishAt != nil {
+		if !req.PublishAt.After(time.Now()) {
+			return nil, commons.ErrInvalidPublishTime
+		}
+		newPost.Status = entities.PostStatusScheduled
+		newPost.PublishAt = req.PublishAt
+	}
+
+	tags, err := postTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	newPost.Tags = tags
+	if newPost.ContentHTML, err = commons.RenderMarkdown(req.Content); err != nil {
+		return nil, err
+	}
+	if err := u.setCategory(ctx, newPost, req.Category); err != nil {
+		return nil, err
+	}
+	if req.CoverID != nil {
+		if err := u.setCover(ctx, principal, newPost, *req.CoverID); err != nil {
+			return nil, err
+		}
+	}
+
+	slug, err := u.uniqueSlug(ctx, req.Title, 0)
+	if err != nil {
+		return nil, err
 	}
+	newPost.Slug = slug
 
 	err = u.postRepo.CreatePost(ctx, newPost)
 	if err != nil {
 		return nil, err
 	}
+	logIndexError(u.searchIndex.IndexPost(ctx, *newPost))
 
-	newPost.Author = user
+	newPost.Author = principal.User()
 
 	return newPost, nil
 }
 
-func (u *postUsecase) GetAllPosts(ctx context.Context, limit, page int) ([]entities.Post, error) {
+// GetAllPosts lists the published posts, only those with the given tag slug and in the
+// given category or one of its subcategories when they are set
+func (u *postUsecase) GetAllPosts(ctx context.Context, tag, category string, limit, page int) ([]entities.Post, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
@@ -75,7 +157,20 @@ func (u *postUsecase) GetAllPosts(ctx context.Context, limit, page int) ([]entit
 	}
 	offset := (page - 1) * limit
 
-	return u.postRepo.GetAllPosts(ctx, limit, offset)
+	filter := entities.PostFilter{TagSlug: tag}
+	if category != "" {
+		categories, err := u.categoryRepo.GetAllCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		root := findCategory(categories, category)
+		if root == nil {
+			return []entities.Post{}, nil
+		}
+		filter.CategoryIDs = categoryWithDescendants(categories, root.ID)
+	}
+
+	return u.postRepo.GetAllPosts(ctx, filter, limit, offset)
 }
 
 func (u *postUsecase) GetPostByID(ctx context.Context, id uint) (*entities.Post, error) {
@@ -86,38 +181,151 @@ func (u *postUsecase) GetPostByID(ctx context.Context, id uint) (*entities.Post,
 		return nil, commons.ErrBadRequest
 	}
 
-	return u.postRepo.GetPostById(ctx, id)
+	post, err := u.postRepo.GetPostById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canReadPost(ctx, post) {
+		return nil, commons.ErrNotFound
+	}
+	return post, nil
 }
 
+// GetPostBySlug looks a post up by its current slug or by one it had before. For an old
+// slug moved is true, so the caller can redirect to the current one.
+func (u *postUsecase) GetPostBySlug(ctx context.Context, slug string) (*entities.Post, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if slug == "" {
+		return nil, false, commons.ErrBadRequest
+	}
+
+	moved := false
+	post, err := u.postRepo.GetPostBySlug(ctx, slug)
+	if err == commons.ErrNotFound {
+		id, err := u.postRepo.GetPostIDByOldSlug(ctx, slug)
+		if err != nil {
+			return nil, false, err
+		}
+		post, err = u.postRepo.GetPostById(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		moved = true
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	// Checked before redirecting, so the new slug of a draft is not revealed either
+	if !canReadPost(ctx, post) {
+		return nil, false, commons.ErrNotFound
+	}
+	return post, moved, nil
+}
+
+// UpdatePost replaces the title and content of a post, and its tags and category when given
 func (u *postUsecase) UpdatePost(ctx context.Context, req *entities.UpdatePostRequest) (*entities.Post, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
-	email := ctx.Value("user").(string)
-	user, err := u.userRepo.FindByEmail(ctx, email)
+	principal, existingPost, err := u.getPostForUpdate(ctx, req.ID, req.Version)
 	if err != nil {
 		return nil, err
 	}
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	return u.updatePost(ctx, principal, existingPost, req)
+}
+
+// GetPostAuthorID returns who wrote the post, for authorizing requests about it. Posts the
+// caller may not read are not found.
+func (u *postUsecase) GetPostAuthorID(ctx context.Context, id uint) (uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	post, err := u.postRepo.GetPostById(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if !canReadPost(ctx, post) {
+		return 0, commons.ErrNotFound
+	}
+	return post.AuthorID, nil
+}
+
+// getPostForUpdate returns the post if the current user may edit it and it still has the
+// version the update was made against
+func (u *postUsecase) getPostForUpdate(ctx context.Context, id, version uint) (commons.Principal, *entities.Post, error) {
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return principal, nil, commons.ErrUnauthorized
+	}
 	// check if the user is the author of the post
-	existingPost, err := u.postRepo.GetPostById(ctx, req.ID)
+	post, err := u.postRepo.GetPostById(ctx, id)
 	if err != nil {
-		return nil, err
+		return principal, nil, err
 	}
-	if existingPost.AuthorID != user.ID {
-		return nil, commons.ErrForbidden
+	if !canEditPost(principal, post) {
+		return principal, nil, commons.ErrForbidden
+	}
+	// The repository checks the version again when saving, this only fails early
+	if version == 0 {
+		return principal, nil, commons.ErrVersionRequired
 	}
+	if version != post.Version {
+		return principal, nil, commons.ErrVersionMismatch
+	}
+	return principal, post, nil
+}
+
+// updatePost applies a validated update to the post and saves it
+func (u *postUsecase) updatePost(ctx context.Context, principal commons.Principal, existingPost *entities.Post, req *entities.UpdatePostRequest) (*entities.Post, error) {
+	// Only new titles and contents are kept as revisions
+	edited := req.Title != existingPost.Title || req.Content != existingPost.Content
 
-	if req.Title != "" {
-		existingPost.Title = req.Title
+	// The slug is saved together with the post, so a rejected update does not change it
+	if req.Title != existingPost.Title || existingPost.Slug == "" {
+		if err := u.setSlug(ctx, existingPost, req.Title); err != nil {
+			return nil, err
+		}
+	}
+	existingPost.Title = req.Title
+	existingPost.Content = req.Content
+
+	var err error
+	if existingPost.ContentHTML, err = commons.RenderMarkdown(req.Content); err != nil {
+		return nil, err
 	}
-	if req.Content != "" {
-		existingPost.Content = req.Content
+	if req.Tags != nil {
+		if existingPost.Tags, err = postTags(*req.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if req.Category != nil {
+		if err := u.setCategory(ctx, existingPost, *req.Category); err != nil {
+			return nil, err
+		}
+	}
+	if req.CoverID != nil {
+		if err := u.setCover(ctx, principal, existingPost, *req.CoverID); err != nil {
+			return nil, err
+		}
 	}
 
-	err = u.postRepo.UpdatePost(ctx, existingPost)
+	if edited {
+		err = u.postRepo.UpdatePostWithRevision(ctx, existingPost, &entities.PostRevision{EditorID: principal.UserID})
+	} else {
+		err = u.postRepo.UpdatePost(ctx, existingPost)
+	}
 	if err != nil {
 		return nil, err
 	}
+	logIndexError(u.searchIndex.IndexPost(ctx, *existingPost))
 
 	return existingPost, nil
 }
@@ -131,14 +339,311 @@ func (u *postUsecase) DeletePost(ctx context.Context, id uint) error {
 		return err
 	}
 
-	email := ctx.Value("user").(string)
-	user, err := u.userRepo.FindByEmail(ctx, email)
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return commons.ErrUnauthorized
+	}
+	if !commons.IsOwnerOrPermitted(principal, post.AuthorID, commons.PermDeleteAnyPost) {
+		return commons.ErrForbidden
+	}
+
+	if err := u.postRepo.DeletePost(ctx, id); err != nil {
+		return err
+	}
+	// The index keeps the post and its comments hidden until it is restored or purged
+	post.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	logIndexError(u.searchIndex.IndexPost(ctx, *post))
+	return nil
+}
+
+func (u *postUsecase) PublishPost(ctx context.Context, id uint) (*entities.Post, error) {
+	return u.changeStatus(ctx, id, entities.PostStatusPublished)
+}
+
+func (u *postUsecase) UnpublishPost(ctx context.Context, id uint) (*entities.Post, error) {
+	return u.changeStatus(ctx, id, entities.PostStatusDraft)
+}
+
+func (u *postUsecase) ArchivePost(ctx context.Context, id uint) (*entities.Post, error) {
+	return u.changeStatus(ctx, id, entities.PostStatusArchived)
+}
+
+// SchedulePost sets a draft or scheduled post to be published at a later time
+func (u *postUsecase) SchedulePost(ctx context.Context, req *entities.SchedulePostRequest) (*entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	post, err := u.getEditablePost(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status != entities.PostStatusDraft && post.Status != entities.PostStatusScheduled {
+		return nil, commons.ErrInvalidPostStatus
+	}
+	if !req.PublishAt.After(time.Now()) {
+		return nil, commons.ErrInvalidPublishTime
+	}
+
+	post.Status = entities.PostStatusScheduled
+	post.PublishAt = &req.PublishAt
+	if err := u.postRepo.UpdatePost(ctx, post); err != nil {
+		return nil, err
+	}
+	logIndexError(u.searchIndex.IndexPost(ctx, *post))
+	return post, nil
+}
+
+// changeStatus moves a post to another status. Published posts keep the time they were
+// first published, going back to draft clears it. Publishing or unpublishing a scheduled
+// post cancels the schedule.
+func (u *postUsecase) changeStatus(ctx context.Context, id uint, status string) (*entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	post, err := u.getEditablePost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case post.Status == status:
+		return post, nil
+	case status == entities.PostStatusArchived && post.Status != entities.PostStatusPublished:
+		return nil, commons.ErrInvalidPostStatus
+	}
+
+	post.Status = status
+	post.PublishAt = nil
+	switch status {
+	case entities.PostStatusPublished:
+		if post.PublishedAt == nil {
+			now := time.Now()
+			post.PublishedAt = &now
+		}
+	case entities.PostStatusDraft:
+		post.PublishedAt = nil
+	}
+
+	if err := u.postRepo.UpdatePost(ctx, post); err != nil {
+		return nil, err
+	}
+	logIndexError(u.searchIndex.IndexPost(ctx, *post))
+	return post, nil
+}
+
+// GetMyPosts lists the posts of the current user of every status, or of the given one
+func (u *postUsecase) GetMyPosts(ctx context.Context, status string, limit, page int) ([]entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+	switch status {
+	case "", entities.PostStatusDraft, entities.PostStatusScheduled, entities.PostStatusPublished, entities.PostStatusArchived:
+	default:
+		return nil, commons.ErrInvalidPostStatus
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+	if page == 0 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	posts, err := u.postRepo.GetPostsByAuthor(ctx, principal.UserID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if posts == nil {
+		posts = []entities.Post{}
+	}
+	return posts, nil
+}
+
+// BackfillSlugs gives the posts created before slugs existed a slug from their title
+func (u *postUsecase) BackfillSlugs(ctx context.Context) (int, error) {
+	filled := 0
+	for {
+		posts, err := u.postRepo.GetPostsWithoutSlug(ctx, slugBackfillBatchSize)
+		if err != nil {
+			return filled, err
+		}
+		if len(posts) == 0 {
+			return filled, nil
+		}
+		for _, post := range posts {
+			slug, err := u.uniqueSlug(ctx, post.Title, post.ID)
+			if err != nil {
+				return filled, err
+			}
+			if err := u.postRepo.ChangeSlug(ctx, post.ID, "", slug); err != nil {
+				return filled, err
+			}
+			filled++
+		}
+	}
+}
+
+// BackfillContentHTML renders the content of the posts created before Markdown rendering
+func (u *postUsecase) BackfillContentHTML(ctx context.Context) (int, error) {
+	filled := 0
+	for {
+		posts, err := u.postRepo.GetPostsWithoutContentHTML(ctx, contentHTMLBackfillBatchSize)
+		if err != nil {
+			return filled, err
+		}
+		if len(posts) == 0 {
+			return filled, nil
+		}
+		for _, post := range posts {
+			html, err := commons.RenderMarkdown(post.Content)
+			if err != nil {
+				return filled, err
+			}
+			if err := u.postRepo.SetContentHTML(ctx, post.ID, html); err != nil {
+				return filled, err
+			}
+			filled++
+		}
+	}
+}
+
+// RenderPreview renders Markdown the way the content of a post is rendered, without saving it
+func (u *postUsecase) RenderPreview(ctx context.Context, req *entities.RenderPreviewRequest) (*entities.RenderPreview, error) {
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	html, err := commons.RenderMarkdown(req.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.RenderPreview{ContentHTML: html}, nil
+}
+
+// setSlug gives a post a slug from its new title. It is changed when the post is saved, and
+// the previous slug keeps redirecting to the post.
+func (u *postUsecase) setSlug(ctx context.Context, post *entities.Post, title string) error {
+	slug, err := u.uniqueSlug(ctx, title, post.ID)
+	if err != nil {
+		return err
+	}
+	post.Slug = slug
+	return nil
+}
+
+// uniqueSlug derives a slug from the title that no other post uses now or used before,
+// adding -2, -3 and so on when needed
+func (u *postUsecase) uniqueSlug(ctx context.Context, title string, postID uint) (string, error) {
+	base := commons.Slugify(title)
+	existing, err := u.postRepo.FindSlugsWithPrefix(ctx, base, postID)
+	if err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool, len(existing))
+	for _, slug := range existing {
+		taken[slug] = true
+	}
+	slug := base
+	for n := 2; taken[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug, nil
+}
+
+// getEditablePost returns the post if the caller may edit it
+func (u *postUsecase) getEditablePost(ctx context.Context, id uint) (*entities.Post, error) {
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+	post, err := u.postRepo.GetPostById(ctx, id)
 	if err != nil {
+		return nil, err
+	}
+	if !canEditPost(principal, post) {
+		// Do not reveal unpublished posts of other authors
+		if !isPublicPost(post) {
+			return nil, commons.ErrNotFound
+		}
+		return nil, commons.ErrForbidden
+	}
+	return post, nil
+}
+
+// isPublicPost reports whether anyone may read the post
+func isPublicPost(post *entities.Post) bool {
+	return post.Status == entities.PostStatusPublished || post.Status == entities.PostStatusArchived
+}
+
+// canReadPost reports whether the caller may see the post. Unpublished posts do not exist
+// for anyone but the people allowed to edit them.
+func canReadPost(ctx context.Context, post *entities.Post) bool {
+	if isPublicPost(post) {
+		return true
+	}
+	principal, _ := commons.PrincipalFromContext(ctx)
+	return canEditPost(principal, post)
+}
+
+// canEditPost reports whether the caller may edit the post and change its status
+func canEditPost(principal commons.Principal, post *entities.Post) bool {
+	return commons.IsOwnerOrPermitted(principal, post.AuthorID, commons.PermUpdateAnyPost)
+}
+
+// setCategory moves a post into the category with the given slug, an empty slug leaves it
+// uncategorized
+func (u *postUsecase) setCategory(ctx context.Context, post *entities.Post, slug string) error {
+	if slug == "" {
+		post.CategoryID = nil
+		post.Category = nil
+		return nil
+	}
+
+	category, err := u.categoryRepo.GetCategoryBySlug(ctx, slug)
+	if err == commons.ErrNotFound {
+		return commons.ErrUnknownCategory
+	} else if err != nil {
 		return err
 	}
-	if post.AuthorID != user.ID {
-		return commons.ErrForbidden
+	post.CategoryID = &category.ID
+	post.Category = &category
+	return nil
+}
+
+// setCover makes the uploaded image with the given ID the cover of a post, 0 removes the
+// cover. The image must have been uploaded by the editor or by the author of the post.
+func (u *postUsecase) setCover(ctx context.Context, principal commons.Principal, post *entities.Post, id uint) error {
+	if id == 0 {
+		post.CoverID = nil
+		post.Cover = nil
+		return nil
 	}
 
-	return u.postRepo.DeletePost(ctx, id)
+	media, err := u.mediaRepo.GetMediaByID(ctx, id)
+	if err == commons.ErrNotFound {
+		return commons.ErrInvalidCover
+	} else if err != nil {
+		return err
+	}
+	if media.OwnerID != principal.UserID && media.OwnerID != post.AuthorID {
+		return commons.ErrInvalidCover
+	}
+	if !strings.HasPrefix(media.ContentType, "image/") {
+		return commons.ErrInvalidCover
+	}
+	post.CoverID = &media.ID
+	post.Cover = media
+	return nil
 }
@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+	"encoding/json"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// postPatchFields maps the members a merge patch of a post may have to the fields of
+// UpdatePostRequest
+var postPatchFields = map[string]string{
+	"title":    "Title",
+	"content":  "Content",
+	"tags":     "Tags",
+	"category": "Category",
+	"cover_id": "CoverID",
+}
+
+// postDocument is the post as a merge patch sees it
+type postDocument struct {
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	Tags     []string `json:"tags"`
+	Category string   `json:"category,omitempty"`
+	CoverID  *uint    `json:"cover_id,omitempty"`
+}
+
+// PatchPost applies a JSON Merge Patch (RFC 7396) to a post. Only the fields in the patch
+// are changed and validated, null removes the tags, the category or the cover.
+func (u *postUsecase) PatchPost(ctx context.Context, id, version uint, patch []byte) (*entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, existingPost, err := u.getPostForUpdate(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil || members == nil {
+		return nil, commons.ErrBadRequest
+	}
+	fields := make([]string, 0, len(members))
+	for name := range members {
+		field, ok := postPatchFields[name]
+		if !ok {
+			return nil, commons.ErrBadRequest
+		}
+		fields = append(fields, field)
+	}
+
+	doc := postDocument{Title: existingPost.Title, Content: existingPost.Content, Tags: []string{}}
+	for _, tag := range existingPost.Tags {
+		doc.Tags = append(doc.Tags, tag.Name)
+	}
+	if existingPost.Category != nil {
+		doc.Category = existingPost.Category.Slug
+	}
+	doc.CoverID = existingPost.CoverID
+	current, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := commons.MergePatch(current, patch)
+	if err != nil {
+		return nil, commons.ErrBadRequest
+	}
+
+	var req entities.UpdatePostRequest
+	if err := json.Unmarshal(merged, &req); err != nil {
+		return nil, commons.ErrBadRequest
+	}
+	req.ID = id
+	req.Version = version
+	// Optional fields the patch leaves alone are kept as they are, those it sets to null are
+	// missing from the merged document and get removed
+	if _, ok := members["tags"]; !ok {
+		req.Tags = nil
+	} else if req.Tags == nil {
+		req.Tags = &[]string{}
+	}
+	if _, ok := members["category"]; !ok {
+		req.Category = nil
+	} else if req.Category == nil {
+		req.Category = new(string)
+	}
+	if _, ok := members["cover_id"]; !ok {
+		req.CoverID = nil
+	} else if req.CoverID == nil {
+		req.CoverID = new(uint)
+	}
+
+	if len(fields) > 0 {
+		validator := validator.New()
+		if err := validator.StructPartial(&req, fields...); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.updatePost(ctx, principal, existingPost, &req)
+}
@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	categoryMocks "app/internal/repositories/category/mocks"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"app/internal/search"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostUsecase_PatchPost(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	mockCategoryRepo := new(categoryMocks.CategoryRepository)
+	timeout := time.Second * 2
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+	categoryID := uint(4)
+
+	tests := []struct {
+		name         string
+		patch        string
+		wantTitle    string
+		wantContent  string
+		wantTags     int
+		wantCategory bool
+		wantErr      error
+		wantInvalid  bool
+		mock         func()
+	}{
+		{
+			name:         "content only",
+			patch:        `{"content":"Gophers"}`,
+			wantTitle:    "Hello",
+			wantContent:  "Gophers",
+			wantTags:     1,
+			wantCategory: true,
+			mock: func() {
+				mockPostRepo.On("UpdatePostWithRevision", mock.Anything, mock.AnythingOfType("*entities.Post"), &entities.PostRevision{EditorID: 1}).Return(nil)
+			},
+		},
+		{
+			name:        "clear optional fields",
+			patch:       `{"tags":null,"category":null}`,
+			wantTitle:   "Hello",
+			wantContent: "World",
+			mock: func() {
+				mockPostRepo.On("UpdatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(nil)
+			},
+		},
+		{
+			name:         "replace tags",
+			patch:        `{"tags":["Go","Web"]}`,
+			wantTitle:    "Hello",
+			wantContent:  "World",
+			wantTags:     2,
+			wantCategory: true,
+			mock: func() {
+				mockPostRepo.On("UpdatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(nil)
+			},
+		},
+		{
+			name:        "remove required field",
+			patch:       `{"title":null}`,
+			wantInvalid: true,
+			mock:        func() {},
+		},
+		{
+			name:        "empty title",
+			patch:       `{"title":""}`,
+			wantInvalid: true,
+			mock:        func() {},
+		},
+		{
+			name:    "unknown field",
+			patch:   `{"author_id":2}`,
+			wantErr: commons.ErrBadRequest,
+			mock:    func() {},
+		},
+		{
+			name:    "wrong type",
+			patch:   `{"title":5}`,
+			wantErr: commons.ErrBadRequest,
+			mock:    func() {},
+		},
+		{
+			name:    "not an object",
+			patch:   `["title"]`,
+			wantErr: commons.ErrBadRequest,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{
+				ID: 3, AuthorID: 1, Slug: "hello", Title: "Hello", Content: "World", Status: entities.PostStatusPublished, Version: 2,
+				CategoryID: &categoryID, Category: &entities.Category{ID: 4, Slug: "backend"}, Tags: []entities.Tag{{ID: 1, Name: "Go", Slug: "go"}},
+			}, nil)
+			tt.mock()
+
+			u := NewPostUsecase(mockPostRepo, mockCategoryRepo, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, err := u.PatchPost(ctx, 3, 2, []byte(tt.patch))
+			if _, invalid := err.(validator.ValidationErrors); invalid != tt.wantInvalid {
+				t.Fatalf("PostUsecase.PatchPost() error = %v, want a validation error %v", err, tt.wantInvalid)
+			}
+			if tt.wantInvalid {
+				return
+			}
+			if err != tt.wantErr {
+				t.Fatalf("PostUsecase.PatchPost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if got.Title != tt.wantTitle || got.Content != tt.wantContent {
+					t.Errorf("PostUsecase.PatchPost() = %q, %q, want %q, %q", got.Title, got.Content, tt.wantTitle, tt.wantContent)
+				}
+				if len(got.Tags) != tt.wantTags || (got.CategoryID != nil) != tt.wantCategory {
+					t.Errorf("PostUsecase.PatchPost() = tags %v category %v", got.Tags, got.CategoryID)
+				}
+			}
+			mockPostRepo.AssertExpectations(t)
+			mockCategoryRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	postRepositories "app/internal/repositories/post"
+	"app/internal/search"
+	"context"
+	"log"
+	"time"
+)
+
+// postPurgerBatchSize limits how many posts are purged in one transaction
+const postPurgerBatchSize = 100
+
+// PostPurger deletes posts for good, with their comments, once they have been in the trash
+// for longer than the retention period
+type PostPurger struct {
+	postRepo    postRepositories.PostRepository
+	searchIndex search.Index
+	retention   time.Duration
+	interval    time.Duration
+}
+
+func NewPostPurger(post postRepositories.PostRepository, index search.Index, retention, interval time.Duration) *PostPurger {
+	return &PostPurger{
+		postRepo:    post,
+		searchIndex: index,
+		retention:   retention,
+		interval:    interval,
+	}
+}
+
+// Run purges expired posts on every tick until ctx is done. Like PostScheduler.Run it only
+// returns between batches.
+func (p *PostPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.PurgeExpired(ctx, time.Now()); err != nil {
+			log.Printf("failed to purge deleted posts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired purges every post that went to the trash before now minus the retention
+// period and returns how many it purged. Like PostScheduler.PublishDue it stops after the
+// current batch when ctx is cancelled.
+func (p *PostPurger) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	batchCtx := context.WithoutCancel(ctx)
+	before := now.Add(-p.retention)
+	purged := 0
+	for ctx.Err() == nil {
+		ids, err := p.postRepo.PurgeDeletedPosts(batchCtx, before, postPurgerBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged += len(ids)
+		for _, id := range ids {
+			logIndexError(p.searchIndex.RemovePost(batchCtx, id))
+		}
+		if len(ids) < postPurgerBatchSize {
+			break
+		}
+	}
+	return purged, nil
+}
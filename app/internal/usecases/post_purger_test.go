@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/search"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostPurger_PurgeExpired(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+	before := now.Add(-retention)
+
+	fullBatch := make([]uint, postPurgerBatchSize)
+	for i := range fullBatch {
+		fullBatch[i] = uint(i + 1)
+	}
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "nothing expired",
+			want: 0,
+			mock: func() {
+				mockPostRepo.On("PurgeDeletedPosts", mock.Anything, before, postPurgerBatchSize).Return(nil, nil).Once()
+			},
+		},
+		{
+			name: "more than one batch",
+			want: postPurgerBatchSize + 2,
+			mock: func() {
+				mockPostRepo.On("PurgeDeletedPosts", mock.Anything, before, postPurgerBatchSize).Return(fullBatch, nil).Once()
+				mockPostRepo.On("PurgeDeletedPosts", mock.Anything, before, postPurgerBatchSize).Return([]uint{101, 102}, nil).Once()
+			},
+		},
+		{
+			name:    "repository error",
+			want:    0,
+			wantErr: commons.ErrTimeout,
+			mock: func() {
+				mockPostRepo.On("PurgeDeletedPosts", mock.Anything, before, postPurgerBatchSize).Return(nil, commons.ErrTimeout).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+
+			tt.mock()
+			p := NewPostPurger(mockPostRepo, search.NewMemoryIndex(), retention, time.Hour)
+			got, err := p.PurgeExpired(context.TODO(), now)
+			if err != tt.wantErr {
+				t.Errorf("PostPurger.PurgeExpired() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PostPurger.PurgeExpired() = %d, want %d", got, tt.want)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostPurger_PurgeExpiredStopsBetweenBatches(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fullBatch := make([]uint, postPurgerBatchSize)
+	for i := range fullBatch {
+		fullBatch[i] = uint(i + 1)
+	}
+	mockPostRepo.On("PurgeDeletedPosts", mock.Anything, mock.Anything, postPurgerBatchSize).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(fullBatch, nil).Once()
+
+	p := NewPostPurger(mockPostRepo, search.NewMemoryIndex(), time.Hour, time.Minute)
+	got, err := p.PurgeExpired(ctx, now)
+	if err != nil || got != postPurgerBatchSize {
+		t.Errorf("PostPurger.PurgeExpired() = %d, %v, want %d after one batch", got, err, postPurgerBatchSize)
+	}
+	mockPostRepo.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"app/internal/search"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostUsecase_CreatePostRendersContent(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "hello", uint(0)).Return(nil, nil)
+	mockPostRepo.On("CreatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(nil)
+
+	u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, time.Second*2)
+	got, err := u.CreatePost(ctx, &entities.CreatePostRequest{Title: "Hello", Content: "**World**<script>alert(1)</script>"})
+	if err != nil {
+		t.Fatalf("PostUsecase.CreatePost() error = %v", err)
+	}
+	if want := "<p><strong>World</strong></p>\n"; got.ContentHTML != want {
+		t.Errorf("PostUsecase.CreatePost() content_html = %q, want %q", got.ContentHTML, want)
+	}
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestPostUsecase_BackfillContentHTML(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+
+	mockPostRepo.On("GetPostsWithoutContentHTML", mock.Anything, contentHTMLBackfillBatchSize).
+		Return([]entities.Post{{ID: 1, Content: "# One"}, {ID: 2, Content: "two"}}, nil).Once()
+	mockPostRepo.On("GetPostsWithoutContentHTML", mock.Anything, contentHTMLBackfillBatchSize).Return(nil, nil).Once()
+	mockPostRepo.On("SetContentHTML", mock.Anything, uint(1), "<h1 id=\"one\">One</h1>\n").Return(nil)
+	mockPostRepo.On("SetContentHTML", mock.Anything, uint(2), "<p>two</p>\n").Return(nil)
+
+	u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, time.Second*2)
+	got, err := u.BackfillContentHTML(context.TODO())
+	if err != nil {
+		t.Fatalf("PostUsecase.BackfillContentHTML() error = %v", err)
+	}
+	if got != 2 {
+		t.Errorf("PostUsecase.BackfillContentHTML() = %d, want 2", got)
+	}
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestPostUsecase_RenderPreview(t *testing.T) {
+	u := NewPostUsecase(new(postMocks.PostRepository), nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, time.Second*2)
+
+	got, err := u.RenderPreview(context.TODO(), &entities.RenderPreviewRequest{Content: "`code`"})
+	if err != nil {
+		t.Fatalf("PostUsecase.RenderPreview() error = %v", err)
+	}
+	if got.ContentHTML != "<p><code>code</code></p>\n" {
+		t.Errorf("PostUsecase.RenderPreview() = %q", got.ContentHTML)
+	}
+
+	if _, err := u.RenderPreview(context.TODO(), &entities.RenderPreviewRequest{}); err == nil {
+		t.Errorf("PostUsecase.RenderPreview() error = nil, want a validation error")
+	} else if _, ok := err.(validator.ValidationErrors); !ok {
+		t.Errorf("PostUsecase.RenderPreview() error = %v, want a validation error", err)
+	}
+}
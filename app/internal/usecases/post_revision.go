@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+)
+
+// GetRevisions lists the revisions of a post, newest first. Only those who may edit the
+// post see its history.
+func (u *postUsecase) GetRevisions(ctx context.Context, postID uint) ([]entities.PostRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.getEditablePost(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := u.postRepo.GetRevisions(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []entities.PostRevision{}
+	}
+	return revisions, nil
+}
+
+// DiffRevisions compares the title and content of two revisions of a post line by line
+func (u *postUsecase) DiffRevisions(ctx context.Context, postID, from, to uint) (*entities.RevisionDiff, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if from == 0 || to == 0 {
+		return nil, commons.ErrBadRequest
+	}
+	if _, err := u.getEditablePost(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	fromRev, err := u.postRepo.GetRevision(ctx, postID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := u.postRepo.GetRevision(ctx, postID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.RevisionDiff{
+		PostID:  postID,
+		From:    from,
+		To:      to,
+		Title:   commons.DiffLines(fromRev.Title, toRev.Title),
+		Content: commons.DiffLines(fromRev.Content, toRev.Content),
+	}, nil
+}
+
+// RestoreRevision brings back the title and content of an earlier revision. The restore
+// is stored as a new revision, so it can be undone the same way.
+func (u *postUsecase) RestoreRevision(ctx context.Context, postID, number uint) (*entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	post, err := u.getEditablePost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	principal, _ := commons.PrincipalFromContext(ctx)
+
+	revision, err := u.postRepo.GetRevision(ctx, postID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision.Title != post.Title {
+		if err := u.setSlug(ctx, post, revision.Title); err != nil {
+			return nil, err
+		}
+	}
+	post.Title = revision.Title
+	post.Content = revision.Content
+	if post.ContentHTML, err = commons.RenderMarkdown(revision.Content); err != nil {
+		return nil, err
+	}
+
+	err = u.postRepo.UpdatePostWithRevision(ctx, post, &entities.PostRevision{EditorID: principal.UserID, RestoredFrom: &number})
+	if err != nil {
+		return nil, err
+	}
+	logIndexError(u.searchIndex.IndexPost(ctx, *post))
+	return post, nil
+}
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"app/internal/search"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostUsecase_GetRevisions(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		status  string
+		wantErr error
+	}{
+		{name: "author", ctx: commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1}), status: entities.PostStatusPublished},
+		{name: "admin", ctx: commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2, Role: commons.RoleAdmin}), status: entities.PostStatusDraft},
+		{name: "another user", ctx: commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2}), status: entities.PostStatusPublished, wantErr: commons.ErrForbidden},
+		{name: "draft of another user", ctx: commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2}), status: entities.PostStatusDraft, wantErr: commons.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1, Status: tt.status}, nil)
+			if tt.wantErr == nil {
+				mockPostRepo.On("GetRevisions", mock.Anything, uint(3)).Return([]entities.PostRevision{{PostID: 3, Number: 1}}, nil)
+			}
+
+			u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			if _, err := u.GetRevisions(tt.ctx, 3); err != tt.wantErr {
+				t.Errorf("PostUsecase.GetRevisions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_DiffRevisions(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusPublished}, nil)
+	mockPostRepo.On("GetRevision", mock.Anything, uint(3), uint(1)).Return(entities.PostRevision{PostID: 3, Number: 1, Title: "Hello", Content: "one\ntwo"}, nil)
+	mockPostRepo.On("GetRevision", mock.Anything, uint(3), uint(2)).Return(entities.PostRevision{PostID: 3, Number: 2, Title: "Hello", Content: "one\nthree"}, nil)
+
+	u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, time.Second*2)
+	got, err := u.DiffRevisions(ctx, 3, 1, 2)
+	if err != nil {
+		t.Fatalf("PostUsecase.DiffRevisions() error = %v", err)
+	}
+	want := []entities.DiffLine{
+		{Op: entities.DiffEqual, Text: "one"},
+		{Op: entities.DiffDelete, Text: "two"},
+		{Op: entities.DiffInsert, Text: "three"},
+	}
+	if len(got.Title) != 1 || got.Title[0].Op != entities.DiffEqual || len(got.Content) != len(want) {
+		t.Fatalf("PostUsecase.DiffRevisions() = %+v", got)
+	}
+	for i := range want {
+		if got.Content[i] != want[i] {
+			t.Errorf("PostUsecase.DiffRevisions() content[%d] = %+v, want %+v", i, got.Content[i], want[i])
+		}
+	}
+
+	if _, err := u.DiffRevisions(ctx, 3, 0, 2); err != commons.ErrBadRequest {
+		t.Errorf("PostUsecase.DiffRevisions() error = %v, wantErr %v", err, commons.ErrBadRequest)
+	}
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestPostUsecase_RestoreRevision(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2, Role: commons.RoleAdmin})
+	restored := uint(1)
+
+	mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1, Slug: "hello-again", Title: "Hello again", Content: "new", Status: entities.PostStatusPublished}, nil)
+	mockPostRepo.On("GetRevision", mock.Anything, uint(3), uint(1)).Return(entities.PostRevision{PostID: 3, Number: 1, Title: "Hello", Content: "old"}, nil)
+	mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "hello", uint(3)).Return([]string{"hello-again"}, nil)
+	mockPostRepo.On("UpdatePostWithRevision", mock.Anything, mock.AnythingOfType("*entities.Post"), &entities.PostRevision{EditorID: 2, RestoredFrom: &restored}).Return(nil)
+
+	u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, time.Second*2)
+	got, err := u.RestoreRevision(ctx, 3, 1)
+	if err != nil {
+		t.Fatalf("PostUsecase.RestoreRevision() error = %v", err)
+	}
+	if got.Title != "Hello" || got.Content != "old" || got.Slug != "hello" {
+		t.Errorf("PostUsecase.RestoreRevision() = %+v", got)
+	}
+	mockPostRepo.AssertExpectations(t)
+}
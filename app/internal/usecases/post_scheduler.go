@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	postRepositories "app/internal/repositories/post"
+	"app/internal/search"
+	"context"
+	"log"
+	"time"
+)
+
+// postSchedulerBatchSize limits how many posts are published in one transaction
+const postSchedulerBatchSize = 100
+
+// PostScheduler publishes scheduled posts once their publish time has passed. Every
+// replica may run one, the repository makes sure each post is only published once.
+type PostScheduler struct {
+	postRepo    postRepositories.PostRepository
+	searchIndex search.Index
+	interval    time.Duration
+}
+
+func NewPostScheduler(post postRepositories.PostRepository, index search.Index, interval time.Duration) *PostScheduler {
+	return &PostScheduler{
+		postRepo:    post,
+		searchIndex: index,
+		interval:    interval,
+	}
+}
+
+// Run publishes due posts on every tick until ctx is done. A batch that is already running
+// when ctx is cancelled is finished first, so Run only returns between batches.
+func (s *PostScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PublishDue(ctx, time.Now()); err != nil {
+			log.Printf("failed to publish scheduled posts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDue publishes every post scheduled at or before now and returns how many it
+// published. When ctx is cancelled it stops after the current batch, which is still
+// committed and indexed; the rest is left for the next run.
+func (s *PostScheduler) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	batchCtx := context.WithoutCancel(ctx)
+	published := 0
+	for ctx.Err() == nil {
+		ids, err := s.postRepo.PublishDuePosts(batchCtx, now, postSchedulerBatchSize)
+		if err != nil {
+			return published, err
+		}
+		published += len(ids)
+		s.indexPublished(batchCtx, ids)
+		if len(ids) < postSchedulerBatchSize {
+			break
+		}
+	}
+	return published, nil
+}
+
+// indexPublished hands the posts that were just published to the search index
+func (s *PostScheduler) indexPublished(ctx context.Context, ids []uint) {
+	for _, id := range ids {
+		post, err := s.postRepo.GetPostById(ctx, id)
+		if err != nil {
+			logIndexError(err)
+			continue
+		}
+		logIndexError(s.searchIndex.IndexPost(ctx, *post))
+	}
+}
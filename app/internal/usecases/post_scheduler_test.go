@@ -0,0 +1,129 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/search"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostScheduler_PublishDue(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	fullBatch := make([]uint, postSchedulerBatchSize)
+	for i := range fullBatch {
+		fullBatch[i] = uint(i + 1)
+	}
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "nothing due",
+			want: 0,
+			mock: func() {
+				mockPostRepo.On("PublishDuePosts", mock.Anything, now, postSchedulerBatchSize).Return(nil, nil).Once()
+			},
+		},
+		{
+			name: "more than one batch",
+			want: postSchedulerBatchSize + 2,
+			mock: func() {
+				mockPostRepo.On("PublishDuePosts", mock.Anything, now, postSchedulerBatchSize).Return(fullBatch, nil).Once()
+				mockPostRepo.On("PublishDuePosts", mock.Anything, now, postSchedulerBatchSize).Return([]uint{101, 102}, nil).Once()
+			},
+		},
+		{
+			name:    "repository error",
+			want:    0,
+			wantErr: commons.ErrTimeout,
+			mock: func() {
+				mockPostRepo.On("PublishDuePosts", mock.Anything, now, postSchedulerBatchSize).Return(nil, commons.ErrTimeout).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			// Published posts are loaded again for the search index
+			mockPostRepo.On("GetPostById", mock.Anything, mock.AnythingOfType("uint")).Return(&entities.Post{Status: entities.PostStatusPublished}, nil).Maybe()
+
+			tt.mock()
+			s := NewPostScheduler(mockPostRepo, search.NewMemoryIndex(), time.Minute)
+			got, err := s.PublishDue(context.TODO(), now)
+			if err != tt.wantErr {
+				t.Errorf("PostScheduler.PublishDue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PostScheduler.PublishDue() = %d, want %d", got, tt.want)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostScheduler_PublishDueStopsBetweenBatches(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fullBatch := make([]uint, postSchedulerBatchSize)
+	for i := range fullBatch {
+		fullBatch[i] = uint(i + 1)
+	}
+	// The shutdown arrives while the first of many batches is running
+	mockPostRepo.On("PublishDuePosts", mock.Anything, now, postSchedulerBatchSize).
+		Run(func(args mock.Arguments) {
+			cancel()
+			if err := args.Get(0).(context.Context).Err(); err != nil {
+				t.Errorf("PublishDuePosts() got a cancelled context for the running batch")
+			}
+		}).
+		Return(fullBatch, nil).Once()
+	mockPostRepo.On("GetPostById", mock.Anything, mock.AnythingOfType("uint")).Return(&entities.Post{Status: entities.PostStatusPublished}, nil)
+
+	s := NewPostScheduler(mockPostRepo, search.NewMemoryIndex(), time.Minute)
+	got, err := s.PublishDue(ctx, now)
+	if err != nil || got != postSchedulerBatchSize {
+		t.Errorf("PostScheduler.PublishDue() = %d, %v, want %d after one batch", got, err, postSchedulerBatchSize)
+	}
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestPostScheduler_RunStopsOnCancel(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	ran := make(chan struct{}, 1)
+	mockPostRepo.On("PublishDuePosts", mock.Anything, mock.Anything, postSchedulerBatchSize).
+		Run(func(mock.Arguments) {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+		}).
+		Return(nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewPostScheduler(mockPostRepo, search.NewMemoryIndex(), time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	<-ran
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("PostScheduler.Run() did not return after the context was cancelled")
+	}
+}
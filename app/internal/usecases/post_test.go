@@ -0,0 +1,616 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	categoryMocks "app/internal/repositories/category/mocks"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"app/internal/search"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostUsecase_CreatePost(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		status        string
+		publishAt     *time.Time
+		wantStatus    string
+		wantPublished bool
+		wantErr       error
+	}{
+		{name: "published by default", status: "", wantStatus: entities.PostStatusPublished, wantPublished: true},
+		{name: "published right away", status: entities.PostStatusPublished, wantStatus: entities.PostStatusPublished, wantPublished: true},
+		{name: "draft", status: entities.PostStatusDraft, wantStatus: entities.PostStatusDraft},
+		{name: "scheduled draft", status: entities.PostStatusDraft, publishAt: &future, wantStatus: entities.PostStatusScheduled},
+		{name: "scheduled", publishAt: &future, wantStatus: entities.PostStatusScheduled},
+		{name: "scheduled in the past", publishAt: &past, wantErr: commons.ErrInvalidPublishTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			if tt.wantErr == nil {
+				mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "hello", uint(0)).Return(nil, nil)
+				mockPostRepo.On("CreatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(nil)
+			}
+
+			u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, err := u.CreatePost(ctx, &entities.CreatePostRequest{Title: "Hello", Content: "World", Status: tt.status, PublishAt: tt.publishAt})
+			if err != tt.wantErr {
+				t.Fatalf("PostUsecase.CreatePost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (got.Status != tt.wantStatus || (got.PublishedAt != nil) != tt.wantPublished) {
+				t.Errorf("PostUsecase.CreatePost() = status %q published at %v, want %q", got.Status, got.PublishedAt, tt.wantStatus)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_CreatePostLongTitle(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+	title := strings.TrimSpace(strings.Repeat("A long title ", 40))
+
+	// Only the slug is cut short, the title is kept whole
+	mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, commons.Slugify(title), uint(0)).Return(nil, nil)
+	mockPostRepo.On("CreatePost", mock.Anything, mock.MatchedBy(func(post *entities.Post) bool {
+		return post.Title == title
+	})).Return(nil)
+
+	u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, time.Second*2)
+	got, err := u.CreatePost(ctx, &entities.CreatePostRequest{Title: title, Content: "World"})
+	if err != nil {
+		t.Fatalf("PostUsecase.CreatePost() error = %v", err)
+	}
+	if len(got.Slug) > 100 {
+		t.Errorf("PostUsecase.CreatePost() slug = %q, longer than its column", got.Slug)
+	}
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestPostUsecase_GetPostByID(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		status  string
+		wantErr error
+	}{
+		{name: "published post", ctx: context.TODO(), status: entities.PostStatusPublished},
+		{name: "archived post", ctx: context.TODO(), status: entities.PostStatusArchived},
+		{name: "draft of the caller", ctx: commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1}), status: entities.PostStatusDraft},
+		{name: "draft as admin", ctx: commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2, Role: commons.RoleAdmin}), status: entities.PostStatusDraft},
+		{name: "draft of another author", ctx: commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2}), status: entities.PostStatusDraft, wantErr: commons.ErrNotFound},
+		{name: "draft for anonymous", ctx: context.TODO(), status: entities.PostStatusDraft, wantErr: commons.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1, Status: tt.status}, nil)
+
+			u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			if _, err := u.GetPostByID(tt.ctx, 3); err != tt.wantErr {
+				t.Errorf("PostUsecase.GetPostByID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_ChangeStatus(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+	author := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+	other := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2})
+	publishedAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Now().Add(time.Hour)
+	schedule := func(at time.Time) func(u PostUsecase, ctx context.Context) (*entities.Post, error) {
+		return func(u PostUsecase, ctx context.Context) (*entities.Post, error) {
+			return u.SchedulePost(ctx, &entities.SchedulePostRequest{ID: 3, PublishAt: at})
+		}
+	}
+
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		post            entities.Post
+		change          func(u PostUsecase, ctx context.Context) (*entities.Post, error)
+		wantStatus      string
+		wantPublishedAt func(*time.Time) bool
+		wantErr         error
+		wantUpdate      bool
+	}{
+		{
+			name:            "publish a draft",
+			ctx:             author,
+			post:            entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusDraft},
+			change:          func(u PostUsecase, ctx context.Context) (*entities.Post, error) { return u.PublishPost(ctx, 3) },
+			wantStatus:      entities.PostStatusPublished,
+			wantPublishedAt: func(at *time.Time) bool { return at != nil && time.Since(*at) < time.Minute },
+			wantUpdate:      true,
+		},
+		{
+			name:            "publish an archived post keeps the publish date",
+			ctx:             author,
+			post:            entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusArchived, PublishedAt: &publishedAt},
+			change:          func(u PostUsecase, ctx context.Context) (*entities.Post, error) { return u.PublishPost(ctx, 3) },
+			wantStatus:      entities.PostStatusPublished,
+			wantPublishedAt: func(at *time.Time) bool { return at != nil && at.Equal(publishedAt) },
+			wantUpdate:      true,
+		},
+		{
+			name:            "unpublish",
+			ctx:             author,
+			post:            entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusPublished, PublishedAt: &publishedAt},
+			change:          func(u PostUsecase, ctx context.Context) (*entities.Post, error) { return u.UnpublishPost(ctx, 3) },
+			wantStatus:      entities.PostStatusDraft,
+			wantPublishedAt: func(at *time.Time) bool { return at == nil },
+			wantUpdate:      true,
+		},
+		{
+			name:            "archive",
+			ctx:             author,
+			post:            entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusPublished, PublishedAt: &publishedAt},
+			change:          func(u PostUsecase, ctx context.Context) (*entities.Post, error) { return u.ArchivePost(ctx, 3) },
+			wantStatus:      entities.PostStatusArchived,
+			wantPublishedAt: func(at *time.Time) bool { return at != nil && at.Equal(publishedAt) },
+			wantUpdate:      true,
+		},
+		{
+			name:            "publish a scheduled post cancels the schedule",
+			ctx:             author,
+			post:            entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusScheduled, PublishAt: &future},
+			change:          func(u PostUsecase, ctx context.Context) (*entities.Post, error) { return u.PublishPost(ctx, 3) },
+			wantStatus:      entities.PostStatusPublished,
+			wantPublishedAt: func(at *time.Time) bool { return at != nil && time.Since(*at) < time.Minute },
+			wantUpdate:      true,
+		},
+		{
+			name:            "schedule a draft",
+			ctx:             author,
+			post:            entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusDraft},
+			change:          schedule(future),
+			wantStatus:      entities.PostStatusScheduled,
+			wantPublishedAt: func(at *time.Time) bool { return at == nil },
+			wantUpdate:      true,
+		},
+		{
+			name:    "schedule in the past",
+			ctx:     author,
+			post:    entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusDraft},
+			change:  schedule(time.Now().Add(-time.Hour)),
+			wantErr: commons.ErrInvalidPublishTime,
+		},
+		{
+			name:    "schedule a published post",
+			ctx:     author,
+			post:    entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusPublished, PublishedAt: &publishedAt},
+			change:  schedule(future),
+			wantErr: commons.ErrInvalidPostStatus,
+		},
+		{
+			name:    "archive a draft",
+			ctx:     author,
+			post:    entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusDraft},
+			change:  func(u PostUsecase, ctx context.Context) (*entities.Post, error) { return u.ArchivePost(ctx, 3) },
+			wantErr: commons.ErrInvalidPostStatus,
+		},
+		{
+			name:    "publish the draft of another author",
+			ctx:     other,
+			post:    entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusDraft},
+			change:  func(u PostUsecase, ctx context.Context) (*entities.Post, error) { return u.PublishPost(ctx, 3) },
+			wantErr: commons.ErrNotFound,
+		},
+		{
+			name:    "unpublish the post of another author",
+			ctx:     other,
+			post:    entities.Post{ID: 3, AuthorID: 1, Status: entities.PostStatusPublished, PublishedAt: &publishedAt},
+			change:  func(u PostUsecase, ctx context.Context) (*entities.Post, error) { return u.UnpublishPost(ctx, 3) },
+			wantErr: commons.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			post := tt.post
+			mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&post, nil)
+			if tt.wantUpdate {
+				mockPostRepo.On("UpdatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(nil)
+			}
+
+			u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, err := tt.change(u, tt.ctx)
+			if err != tt.wantErr {
+				t.Errorf("status change error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && (got.Status != tt.wantStatus || !tt.wantPublishedAt(got.PublishedAt)) {
+				t.Errorf("status change = status %q published at %v, want %q", got.Status, got.PublishedAt, tt.wantStatus)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_UpdatePostVersion(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	tests := []struct {
+		name    string
+		version uint
+		wantErr error
+		mock    func()
+	}{
+		{
+			name:    "current version",
+			version: 4,
+			mock: func() {
+				mockPostRepo.On("UpdatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(nil)
+			},
+		},
+		{
+			name:    "changed meanwhile",
+			version: 4,
+			wantErr: commons.ErrVersionMismatch,
+			mock: func() {
+				mockPostRepo.On("UpdatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(commons.ErrVersionMismatch)
+			},
+		},
+		{
+			name:    "stale version",
+			version: 3,
+			wantErr: commons.ErrVersionMismatch,
+			mock:    func() {},
+		},
+		{
+			name:    "no version",
+			wantErr: commons.ErrVersionRequired,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1, Slug: "hello", Title: "Hello", Content: "World", Status: entities.PostStatusPublished, Version: 4}, nil)
+			tt.mock()
+
+			u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			_, err := u.UpdatePost(ctx, &entities.UpdatePostRequest{ID: 3, Title: "Hello", Content: "World", Version: tt.version})
+			if err != tt.wantErr {
+				t.Errorf("PostUsecase.UpdatePost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_UpdatePostSlug(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	tests := []struct {
+		name     string
+		slug     string
+		title    string
+		wantSlug string
+		mock     func()
+	}{
+		{
+			name:     "new title",
+			slug:     "hello",
+			title:    "Hello again",
+			wantSlug: "hello-again",
+			mock: func() {
+				mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "hello-again", uint(3)).Return(nil, nil)
+			},
+		},
+		{
+			name:     "new title taken by other posts",
+			slug:     "hello",
+			title:    "Grüße, Welt",
+			wantSlug: "grusse-welt-3",
+			mock: func() {
+				mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "grusse-welt", uint(3)).Return([]string{"grusse-welt", "grusse-welt-2", "grusse-welt-extra"}, nil)
+			},
+		},
+		{
+			name:     "title change with the same slug",
+			slug:     "hello",
+			title:    "Hello!",
+			wantSlug: "hello",
+			mock: func() {
+				mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "hello", uint(3)).Return([]string{"hello-again"}, nil)
+			},
+		},
+		{
+			name:     "same title",
+			slug:     "hello",
+			title:    "Hello",
+			wantSlug: "hello",
+			mock:     func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1, Slug: tt.slug, Title: "Hello", Status: entities.PostStatusPublished, Version: 1}, nil)
+			// The new slug is only saved together with the rest of the post
+			mockPostRepo.On("UpdatePostWithRevision", mock.Anything, mock.MatchedBy(func(post *entities.Post) bool {
+				return post.Slug == tt.wantSlug
+			}), &entities.PostRevision{EditorID: 1}).Return(nil)
+			tt.mock()
+
+			u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, err := u.UpdatePost(ctx, &entities.UpdatePostRequest{ID: 3, Title: tt.title, Content: "World", Version: 1})
+			if err != nil {
+				t.Fatalf("PostUsecase.UpdatePost() error = %v", err)
+			}
+			if got.Slug != tt.wantSlug {
+				t.Errorf("PostUsecase.UpdatePost() slug = %q, want %q", got.Slug, tt.wantSlug)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_UpdatePostSlugRejected(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1, Slug: "hello", Title: "Hello", Status: entities.PostStatusPublished, Version: 1}, nil)
+	mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "hello-again", uint(3)).Return(nil, nil)
+	mockPostRepo.On("UpdatePostWithRevision", mock.Anything, mock.AnythingOfType("*entities.Post"), &entities.PostRevision{EditorID: 1}).Return(commons.ErrVersionMismatch)
+
+	u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, time.Second*2)
+	if _, err := u.UpdatePost(ctx, &entities.UpdatePostRequest{ID: 3, Title: "Hello again", Content: "World", Version: 1}); err != commons.ErrVersionMismatch {
+		t.Fatalf("PostUsecase.UpdatePost() error = %v, wantErr %v", err, commons.ErrVersionMismatch)
+	}
+	mockPostRepo.AssertNotCalled(t, "ChangeSlug", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestPostUsecase_GetPostBySlug(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+
+	tests := []struct {
+		name      string
+		slug      string
+		wantID    uint
+		wantMoved bool
+		wantErr   error
+		mock      func()
+	}{
+		{
+			name:   "current slug",
+			slug:   "hello-again",
+			wantID: 3,
+			mock: func() {
+				mockPostRepo.On("GetPostBySlug", mock.Anything, "hello-again").Return(&entities.Post{ID: 3, Slug: "hello-again", Status: entities.PostStatusPublished}, nil)
+			},
+		},
+		{
+			name:      "old slug",
+			slug:      "hello",
+			wantID:    3,
+			wantMoved: true,
+			mock: func() {
+				mockPostRepo.On("GetPostBySlug", mock.Anything, "hello").Return(nil, commons.ErrNotFound)
+				mockPostRepo.On("GetPostIDByOldSlug", mock.Anything, "hello").Return(uint(3), nil)
+				mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, Slug: "hello-again", Status: entities.PostStatusPublished}, nil)
+			},
+		},
+		{
+			name:    "old slug of a draft",
+			slug:    "hello",
+			wantErr: commons.ErrNotFound,
+			mock: func() {
+				mockPostRepo.On("GetPostBySlug", mock.Anything, "hello").Return(nil, commons.ErrNotFound)
+				mockPostRepo.On("GetPostIDByOldSlug", mock.Anything, "hello").Return(uint(3), nil)
+				mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1, Slug: "hello-again", Status: entities.PostStatusDraft}, nil)
+			},
+		},
+		{
+			name:    "unknown slug",
+			slug:    "nope",
+			wantErr: commons.ErrNotFound,
+			mock: func() {
+				mockPostRepo.On("GetPostBySlug", mock.Anything, "nope").Return(nil, commons.ErrNotFound)
+				mockPostRepo.On("GetPostIDByOldSlug", mock.Anything, "nope").Return(uint(0), commons.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			tt.mock()
+
+			u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, moved, err := u.GetPostBySlug(context.TODO(), tt.slug)
+			if err != tt.wantErr {
+				t.Errorf("PostUsecase.GetPostBySlug() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && (got.ID != tt.wantID || moved != tt.wantMoved) {
+				t.Errorf("PostUsecase.GetPostBySlug() = post %d moved %v, want post %d moved %v", got.ID, moved, tt.wantID, tt.wantMoved)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_CreatePostTaxonomy(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	mockCategoryRepo := new(categoryMocks.CategoryRepository)
+	timeout := time.Second * 2
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	tests := []struct {
+		name     string
+		tags     []string
+		category string
+		wantTags []entities.Tag
+		wantErr  error
+		mock     func()
+	}{
+		{
+			name:     "tags and category",
+			tags:     []string{"Go", " go ", "Web Dev"},
+			category: "backend",
+			wantTags: []entities.Tag{{Name: "Go", Slug: "go"}, {Name: "Web Dev", Slug: "web-dev"}},
+			mock: func() {
+				mockCategoryRepo.On("GetCategoryBySlug", mock.Anything, "backend").Return(entities.Category{ID: 4, Name: "Backend", Slug: "backend"}, nil)
+				mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, "hello", uint(0)).Return(nil, nil)
+				mockPostRepo.On("CreatePost", mock.Anything, mock.MatchedBy(func(p *entities.Post) bool {
+					return p.CategoryID != nil && *p.CategoryID == 4 && len(p.Tags) == 2
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "unknown category",
+			category: "nope",
+			wantErr:  commons.ErrUnknownCategory,
+			mock: func() {
+				mockCategoryRepo.On("GetCategoryBySlug", mock.Anything, "nope").Return(entities.Category{}, commons.ErrNotFound)
+			},
+		},
+		{
+			name:    "tag without letters",
+			tags:    []string{"!!!"},
+			wantErr: commons.ErrInvalidTagName,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockCategoryRepo.ExpectedCalls = nil
+			tt.mock()
+
+			u := NewPostUsecase(mockPostRepo, mockCategoryRepo, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, err := u.CreatePost(ctx, &entities.CreatePostRequest{Title: "Hello", Content: "World", Tags: tt.tags, Category: tt.category})
+			if err != tt.wantErr {
+				t.Fatalf("PostUsecase.CreatePost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got.Tags, tt.wantTags) {
+				t.Errorf("PostUsecase.CreatePost() tags = %v, want %v", got.Tags, tt.wantTags)
+			}
+			mockPostRepo.AssertExpectations(t)
+			mockCategoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_UpdatePostTaxonomy(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	mockCategoryRepo := new(categoryMocks.CategoryRepository)
+	timeout := time.Second * 2
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+	categoryID := uint(4)
+	noTags := []string{}
+	uncategorized := ""
+
+	tests := []struct {
+		name         string
+		req          entities.UpdatePostRequest
+		wantTags     int
+		wantCategory bool
+	}{
+		{name: "unchanged", req: entities.UpdatePostRequest{ID: 3, Title: "Hello", Content: "World", Version: 1}, wantTags: 1, wantCategory: true},
+		{name: "cleared", req: entities.UpdatePostRequest{ID: 3, Title: "Hello", Content: "World", Tags: &noTags, Category: &uncategorized, Version: 1}, wantTags: 0, wantCategory: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&entities.Post{
+				ID: 3, AuthorID: 1, Slug: "hello", Title: "Hello", Content: "World", Status: entities.PostStatusPublished, Version: 1,
+				CategoryID: &categoryID, Tags: []entities.Tag{{ID: 1, Name: "Go", Slug: "go"}},
+			}, nil)
+			mockPostRepo.On("UpdatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).Return(nil)
+
+			u := NewPostUsecase(mockPostRepo, mockCategoryRepo, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, err := u.UpdatePost(ctx, &tt.req)
+			if err != nil {
+				t.Fatalf("PostUsecase.UpdatePost() error = %v", err)
+			}
+			if len(got.Tags) != tt.wantTags || (got.CategoryID != nil) != tt.wantCategory {
+				t.Errorf("PostUsecase.UpdatePost() = tags %v category %v", got.Tags, got.CategoryID)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_GetAllPostsFilter(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	mockCategoryRepo := new(categoryMocks.CategoryRepository)
+	timeout := time.Second * 2
+	backend, databases := uint(1), uint(2)
+	categories := []entities.Category{
+		{ID: backend, Name: "Backend", Slug: "backend"},
+		{ID: databases, Name: "Databases", Slug: "databases", ParentID: &backend},
+		{ID: 3, Name: "MySQL", Slug: "mysql", ParentID: &databases},
+		{ID: 4, Name: "Frontend", Slug: "frontend"},
+	}
+
+	tests := []struct {
+		name       string
+		tag        string
+		category   string
+		wantFilter *entities.PostFilter
+	}{
+		{name: "no filter", wantFilter: &entities.PostFilter{}},
+		{name: "tag", tag: "go", wantFilter: &entities.PostFilter{TagSlug: "go"}},
+		{name: "category with subcategories", tag: "go", category: "backend", wantFilter: &entities.PostFilter{TagSlug: "go", CategoryIDs: []uint{1, 2, 3}}},
+		{name: "unknown category", category: "nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+			mockCategoryRepo.ExpectedCalls = nil
+			if tt.category != "" {
+				mockCategoryRepo.On("GetAllCategories", mock.Anything).Return(categories, nil)
+			}
+			if tt.wantFilter != nil {
+				mockPostRepo.On("GetAllPosts", mock.Anything, *tt.wantFilter, 10, 0).Return([]entities.Post{}, nil)
+			}
+
+			u := NewPostUsecase(mockPostRepo, mockCategoryRepo, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			got, err := u.GetAllPosts(context.TODO(), tt.tag, tt.category, 0, 1)
+			if err != nil || got == nil {
+				t.Fatalf("PostUsecase.GetAllPosts() = %v, %v", got, err)
+			}
+			mockPostRepo.AssertExpectations(t)
+			mockCategoryRepo.AssertExpectations(t)
+		})
+	}
+}
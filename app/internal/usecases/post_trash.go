@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// RestorePost takes a post out of the trash. Like deleting, it is up to the author or
+// those who may delete any post.
+func (u *postUsecase) RestorePost(ctx context.Context, id uint) (*entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+
+	post, err := u.postRepo.GetTrashedPostByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !commons.IsOwnerOrPermitted(principal, post.AuthorID, commons.PermDeleteAnyPost) {
+		return nil, commons.ErrForbidden
+	}
+
+	if err := u.postRepo.RestorePost(ctx, id); err != nil {
+		return nil, err
+	}
+	post.DeletedAt = gorm.DeletedAt{}
+	logIndexError(u.searchIndex.IndexPost(ctx, *post))
+	return post, nil
+}
+
+// GetTrashedPostAuthorID returns who wrote a post in the trash, for authorizing requests
+// about it
+func (u *postUsecase) GetTrashedPostAuthorID(ctx context.Context, id uint) (uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	post, err := u.postRepo.GetTrashedPostByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return post.AuthorID, nil
+}
+
+// GetMyTrash lists the posts of the current user in the trash, last deleted first
+func (u *postUsecase) GetMyTrash(ctx context.Context, limit, page int) ([]entities.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+	if page == 0 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	posts, err := u.postRepo.GetTrashedPostsByAuthor(ctx, principal.UserID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if posts == nil {
+		posts = []entities.Post{}
+	}
+	return posts, nil
+}
@@ -0,0 +1,133 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"app/internal/search"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostUsecase_RestorePost(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "author",
+			ctx:  commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1}),
+			mock: func() {
+				mockPostRepo.On("GetTrashedPostByID", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1}, nil)
+				mockPostRepo.On("RestorePost", mock.Anything, uint(3)).Return(nil)
+			},
+		},
+		{
+			name: "admin",
+			ctx:  commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2, Role: commons.RoleAdmin}),
+			mock: func() {
+				mockPostRepo.On("GetTrashedPostByID", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1}, nil)
+				mockPostRepo.On("RestorePost", mock.Anything, uint(3)).Return(nil)
+			},
+		},
+		{
+			name:    "another user",
+			ctx:     commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2}),
+			wantErr: commons.ErrForbidden,
+			mock: func() {
+				mockPostRepo.On("GetTrashedPostByID", mock.Anything, uint(3)).Return(&entities.Post{ID: 3, AuthorID: 1}, nil)
+			},
+		},
+		{
+			name:    "not in the trash",
+			ctx:     commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1}),
+			wantErr: commons.ErrNotFound,
+			mock: func() {
+				mockPostRepo.On("GetTrashedPostByID", mock.Anything, uint(3)).Return(nil, commons.ErrNotFound)
+			},
+		},
+		{
+			name:    "unauthenticated",
+			ctx:     context.TODO(),
+			wantErr: commons.ErrUnauthorized,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, timeout)
+			if _, err := u.RestorePost(tt.ctx, 3); err != tt.wantErr {
+				t.Errorf("PostUsecase.RestorePost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUsecase_TrashHidesFromSearch(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+	post := entities.Post{ID: 3, AuthorID: 1, Title: "Hello", Content: "Gophers everywhere", Status: entities.PostStatusPublished}
+
+	index := search.NewMemoryIndex()
+	index.IndexPost(ctx, post)
+	found := func() int {
+		results, err := index.Search(ctx, entities.SearchQuery{Text: "gophers"}, 10, 0)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		return len(results)
+	}
+
+	mockPostRepo.On("GetPostById", mock.Anything, uint(3)).Return(&post, nil)
+	mockPostRepo.On("DeletePost", mock.Anything, uint(3)).Return(nil)
+	mockPostRepo.On("GetTrashedPostByID", mock.Anything, uint(3)).Return(&post, nil)
+	mockPostRepo.On("RestorePost", mock.Anything, uint(3)).Return(nil)
+
+	u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), index, false, time.Second*2)
+	if err := u.DeletePost(ctx, 3); err != nil {
+		t.Fatalf("PostUsecase.DeletePost() error = %v", err)
+	}
+	if n := found(); n != 0 {
+		t.Errorf("trashed post found %d times, want 0", n)
+	}
+	if _, err := u.RestorePost(ctx, 3); err != nil {
+		t.Fatalf("PostUsecase.RestorePost() error = %v", err)
+	}
+	if n := found(); n != 1 {
+		t.Errorf("restored post found %d times, want 1", n)
+	}
+}
+
+func TestPostUsecase_GetMyTrash(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	mockPostRepo.On("GetTrashedPostsByAuthor", mock.Anything, uint(1), 10, 10).Return(nil, nil)
+
+	u := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), search.NewMemoryIndex(), false, time.Second*2)
+	got, err := u.GetMyTrash(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("PostUsecase.GetMyTrash() error = %v", err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("PostUsecase.GetMyTrash() = %v, want an empty list", got)
+	}
+	if _, err := u.GetMyTrash(context.TODO(), 0, 0); err != commons.ErrUnauthorized {
+		t.Errorf("PostUsecase.GetMyTrash() error = %v, wantErr %v", err, commons.ErrUnauthorized)
+	}
+	mockPostRepo.AssertExpectations(t)
+}
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	postRepositories "app/internal/repositories/post"
+	userRepositories "app/internal/repositories/user"
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const latestPostsOnProfile = 5
+
+type ProfileUsecase interface {
+	GetMe(ctx context.Context) (entities.User, error)
+	UpdateMe(ctx context.Context, req *entities.UpdateProfileRequest) (entities.User, error)
+	GetProfile(ctx context.Context, id uint) (entities.UserProfile, error)
+}
+
+type profileUsecase struct {
+	userRepo       userRepositories.UserRepository
+	postRepo       postRepositories.PostRepository
+	contextTimeout time.Duration
+}
+
+func NewProfileUsecase(user userRepositories.UserRepository, post postRepositories.PostRepository, timeout time.Duration) ProfileUsecase {
+	return &profileUsecase{
+		userRepo:       user,
+		postRepo:       post,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *profileUsecase) GetMe(ctx context.Context) (entities.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return entities.User{}, commons.ErrUnauthorized
+	}
+
+	return u.userRepo.FindByID(ctx, principal.UserID)
+}
+
+func (u *profileUsecase) UpdateMe(ctx context.Context, req *entities.UpdateProfileRequest) (entities.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return entities.User{}, commons.ErrUnauthorized
+	}
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.User{}, err
+	}
+
+	if err := u.userRepo.UpdateProfile(ctx, principal.UserID, *req); err != nil {
+		return entities.User{}, err
+	}
+
+	return u.userRepo.FindByID(ctx, principal.UserID)
+}
+
+func (u *profileUsecase) GetProfile(ctx context.Context, id uint) (entities.UserProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if id == 0 {
+		return entities.UserProfile{}, commons.ErrBadRequest
+	}
+
+	user, err := u.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return entities.UserProfile{}, err
+	}
+
+	count, err := u.postRepo.CountPostsByAuthor(ctx, id, entities.PostStatusPublished)
+	if err != nil {
+		return entities.UserProfile{}, err
+	}
+	posts, err := u.postRepo.GetPostsByAuthor(ctx, id, entities.PostStatusPublished, latestPostsOnProfile, 0)
+	if err != nil {
+		return entities.UserProfile{}, err
+	}
+	if posts == nil {
+		posts = []entities.Post{}
+	}
+
+	return entities.UserProfile{
+		ID:          user.ID,
+		Name:        user.Name,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		PostCount:   count,
+		LatestPosts: posts,
+		CreatedAt:   user.CreatedAt,
+	}, nil
+}
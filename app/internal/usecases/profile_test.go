@@ -0,0 +1,140 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProfileUsecase_GetProfile(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+
+	createdAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	posts := []entities.Post{{ID: 3, Title: "Hello", AuthorID: 1}}
+
+	tests := []struct {
+		name    string
+		id      uint
+		want    entities.UserProfile
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success",
+			id:   1,
+			want: entities.UserProfile{
+				ID:          1,
+				Name:        "John Doe",
+				Bio:         "Gopher",
+				PostCount:   12,
+				LatestPosts: posts,
+				CreatedAt:   createdAt,
+			},
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Name: "John Doe", Email: "john@example.com", Bio: "Gopher", CreatedAt: createdAt}, nil)
+				mockPostRepo.On("CountPostsByAuthor", mock.Anything, uint(1), entities.PostStatusPublished).Return(int64(12), nil)
+				mockPostRepo.On("GetPostsByAuthor", mock.Anything, uint(1), entities.PostStatusPublished, latestPostsOnProfile, 0).Return(posts, nil)
+			},
+		},
+		{
+			name:    "user not found",
+			id:      2,
+			want:    entities.UserProfile{},
+			wantErr: commons.ErrNotFound,
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(2)).Return(entities.User{}, commons.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockPostRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewProfileUsecase(mockRepo, mockPostRepo, timeout)
+			got, err := u.GetProfile(context.TODO(), tt.id)
+			if err != tt.wantErr {
+				t.Errorf("ProfileUsecase.GetProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProfileUsecase.GetProfile() = %v, want %v", got, tt.want)
+			}
+			mockRepo.AssertExpectations(t)
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProfileUsecase_UpdateMe(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockPostRepo := new(postMocks.PostRepository)
+	timeout := time.Second * 2
+
+	bio := "Gopher"
+	badURL := "not a url"
+	scriptURL := "javascript:alert(document.cookie)"
+	noAvatar := ""
+
+	tests := []struct {
+		name    string
+		req     *entities.UpdateProfileRequest
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "only provided fields are updated",
+			req:  &entities.UpdateProfileRequest{Bio: &bio},
+			mock: func() {
+				mockRepo.On("UpdateProfile", mock.Anything, uint(1), entities.UpdateProfileRequest{Bio: &bio}).Return(nil)
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Bio: bio}, nil)
+			},
+		},
+		{
+			name:    "invalid avatar url",
+			req:     &entities.UpdateProfileRequest{AvatarURL: &badURL},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name:    "avatar url with another scheme",
+			req:     &entities.UpdateProfileRequest{AvatarURL: &scriptURL},
+			wantErr: true,
+			mock:    func() {},
+		},
+		{
+			name: "empty avatar url removes the avatar",
+			req:  &entities.UpdateProfileRequest{AvatarURL: &noAvatar},
+			mock: func() {
+				mockRepo.On("UpdateProfile", mock.Anything, uint(1), entities.UpdateProfileRequest{AvatarURL: &noAvatar}).Return(nil)
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewProfileUsecase(mockRepo, mockPostRepo, timeout)
+			ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+			_, err := u.UpdateMe(ctx, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ProfileUsecase.UpdateMe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
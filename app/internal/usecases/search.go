@@ -0,0 +1,112 @@
+package usecases
+
+import (
+	"app/internal/entities"
+	commentRepositories "app/internal/repositories/comment"
+	postRepositories "app/internal/repositories/post"
+	"app/internal/search"
+	"context"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// searchReindexBatchSize is how many posts or comments are loaded at once when reindexing
+const searchReindexBatchSize = 100
+
+type SearchUsecase interface {
+	Search(ctx context.Context, query *entities.SearchQuery, limit, page int) ([]entities.SearchResult, error)
+	Reindex(ctx context.Context) (int, error)
+}
+
+type searchUsecase struct {
+	index          search.Index
+	postRepo       postRepositories.PostRepository
+	commentRepo    commentRepositories.CommentRepository
+	contextTimeout time.Duration
+}
+
+func NewSearchUsecase(index search.Index, post postRepositories.PostRepository, comment commentRepositories.CommentRepository, timeout time.Duration) SearchUsecase {
+	return &searchUsecase{
+		index:          index,
+		postRepo:       post,
+		commentRepo:    comment,
+		contextTimeout: timeout,
+	}
+}
+
+// Search finds published posts and their comments, most relevant first
+func (u *searchUsecase) Search(ctx context.Context, query *entities.SearchQuery, limit, page int) ([]entities.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(query); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	if page <= 0 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	results, err := u.index.Search(ctx, *query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []entities.SearchResult{}
+	}
+	return results, nil
+}
+
+// Reindex hands every post and comment to the index and returns how many it handed over.
+// Indexes that do not live in the database need it on every start.
+func (u *searchUsecase) Reindex(ctx context.Context) (int, error) {
+	count := 0
+	for lastID := uint(0); ; {
+		posts, err := u.postRepo.GetPostsAfterID(ctx, lastID, searchReindexBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, post := range posts {
+			if err := u.index.IndexPost(ctx, post); err != nil {
+				return count, err
+			}
+			lastID = post.ID
+		}
+		count += len(posts)
+		if len(posts) < searchReindexBatchSize {
+			break
+		}
+	}
+
+	for lastID := uint(0); ; {
+		comments, err := u.commentRepo.GetCommentsAfterID(ctx, lastID, searchReindexBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, comment := range comments {
+			if err := u.index.IndexComment(ctx, comment); err != nil {
+				return count, err
+			}
+			lastID = comment.ID
+		}
+		count += len(comments)
+		if len(comments) < searchReindexBatchSize {
+			return count, nil
+		}
+	}
+}
+
+// logIndexError reports a failed search index update. The change itself is already saved,
+// so it does not fail the request.
+func logIndexError(err error) {
+	if err != nil {
+		log.Printf("failed to update the search index: %v", err)
+	}
+}
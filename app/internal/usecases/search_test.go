@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	commentMocks "app/internal/repositories/comment/mocks"
+	postMocks "app/internal/repositories/post/mocks"
+	"app/internal/repositories/user/mocks"
+	"app/internal/search"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchUsecase_Search(t *testing.T) {
+	timeout := time.Second * 2
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+	mockPostRepo := new(postMocks.PostRepository)
+	nextID := uint(0)
+	mockPostRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything, uint(0)).Return(nil, nil)
+	mockPostRepo.On("CreatePost", mock.Anything, mock.AnythingOfType("*entities.Post")).
+		Run(func(args mock.Arguments) {
+			nextID++
+			args.Get(1).(*entities.Post).ID = nextID
+		}).
+		Return(nil)
+
+	index := search.NewMemoryIndex()
+	posts := NewPostUsecase(mockPostRepo, nil, nil, new(mocks.UserRepository), index, false, timeout)
+	u := NewSearchUsecase(index, mockPostRepo, nil, timeout)
+
+	if _, err := posts.CreatePost(ctx, &entities.CreatePostRequest{Title: "Search engines", Content: "Inverted indexes", Status: entities.PostStatusPublished}); err != nil {
+		t.Fatalf("PostUsecase.CreatePost() error = %v", err)
+	}
+	if _, err := posts.CreatePost(ctx, &entities.CreatePostRequest{Title: "Search drafts", Content: "Not public"}); err != nil {
+		t.Fatalf("PostUsecase.CreatePost() error = %v", err)
+	}
+
+	got, err := u.Search(context.TODO(), &entities.SearchQuery{Text: "inverted"}, 0, 0)
+	if err != nil {
+		t.Fatalf("SearchUsecase.Search() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Snippet != "<mark>Inverted</mark> indexes" {
+		t.Errorf("SearchUsecase.Search() = %+v", got)
+	}
+
+	_, err = u.Search(context.TODO(), &entities.SearchQuery{}, 0, 0)
+	if _, ok := err.(validator.ValidationErrors); !ok {
+		t.Errorf("SearchUsecase.Search() error = %v, want a validation error", err)
+	}
+}
+
+func TestSearchUsecase_Reindex(t *testing.T) {
+	mockPostRepo := new(postMocks.PostRepository)
+	mockCommentRepo := new(commentMocks.CommentRepository)
+
+	fullBatch := make([]entities.Post, searchReindexBatchSize)
+	for i := range fullBatch {
+		fullBatch[i] = entities.Post{ID: uint(i + 1), Title: "Filler", Status: entities.PostStatusPublished}
+	}
+	last := entities.Post{ID: 101, Title: "Reindexed", Content: "Found again", Status: entities.PostStatusPublished}
+	mockPostRepo.On("GetPostsAfterID", mock.Anything, uint(0), searchReindexBatchSize).Return(fullBatch, nil)
+	mockPostRepo.On("GetPostsAfterID", mock.Anything, uint(100), searchReindexBatchSize).Return([]entities.Post{last}, nil)
+	mockCommentRepo.On("GetCommentsAfterID", mock.Anything, uint(0), searchReindexBatchSize).
+		Return([]entities.Comment{{ID: 1, PostID: 101, Content: "Reindexed comment"}}, nil)
+
+	index := search.NewMemoryIndex()
+	u := NewSearchUsecase(index, mockPostRepo, mockCommentRepo, time.Second*2)
+	n, err := u.Reindex(context.TODO())
+	if err != nil || n != searchReindexBatchSize+2 {
+		t.Fatalf("SearchUsecase.Reindex() = %d, %v", n, err)
+	}
+
+	got, _ := u.Search(context.TODO(), &entities.SearchQuery{Text: "reindexed"}, 10, 1)
+	if len(got) != 2 {
+		t.Errorf("SearchUsecase.Search() = %+v, want the post and its comment", got)
+	}
+	mockPostRepo.AssertExpectations(t)
+	mockCommentRepo.AssertExpectations(t)
+}
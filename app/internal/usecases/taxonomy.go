@@ -0,0 +1,163 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	categoryRepositories "app/internal/repositories/category"
+	tagRepositories "app/internal/repositories/tag"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type TaxonomyUsecase interface {
+	GetTags(ctx context.Context) ([]entities.TagCount, error)
+	GetCategories(ctx context.Context) ([]entities.CategoryNode, error)
+	CreateCategory(ctx context.Context, req *entities.CreateCategoryRequest) (*entities.Category, error)
+}
+
+type taxonomyUsecase struct {
+	tagRepo        tagRepositories.TagRepository
+	categoryRepo   categoryRepositories.CategoryRepository
+	contextTimeout time.Duration
+}
+
+func NewTaxonomyUsecase(tag tagRepositories.TagRepository, category categoryRepositories.CategoryRepository, timeout time.Duration) TaxonomyUsecase {
+	return &taxonomyUsecase{
+		tagRepo:        tag,
+		categoryRepo:   category,
+		contextTimeout: timeout,
+	}
+}
+
+// GetTags returns the tags in use by published posts with their usage counts
+func (u *taxonomyUsecase) GetTags(ctx context.Context) ([]entities.TagCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	tags, err := u.tagRepo.GetTagsWithCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []entities.TagCount{}
+	}
+	return tags, nil
+}
+
+// GetCategories returns the category tree, top level categories first
+func (u *taxonomyUsecase) GetCategories(ctx context.Context) ([]entities.CategoryNode, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	categories, err := u.categoryRepo.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories, nil), nil
+}
+
+func (u *taxonomyUsecase) CreateCategory(ctx context.Context, req *entities.CreateCategoryRequest) (*entities.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, commons.ErrUnauthorized
+	}
+	if !commons.HasPermission(principal.Role, commons.PermManageCategories) {
+		return nil, commons.ErrForbidden
+	}
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	slug := commons.SlugifyName(name)
+	if slug == "" {
+		return nil, commons.ErrInvalidTagName
+	}
+	if _, err := u.categoryRepo.GetCategoryBySlug(ctx, slug); err == nil {
+		return nil, commons.ErrCategoryExists
+	} else if err != commons.ErrNotFound {
+		return nil, err
+	}
+	if req.ParentID != nil {
+		if _, err := u.categoryRepo.GetCategoryByID(ctx, *req.ParentID); err == commons.ErrNotFound {
+			return nil, commons.ErrUnknownCategory
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	category := &entities.Category{Name: name, Slug: slug, ParentID: req.ParentID}
+	if err := u.categoryRepo.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// buildCategoryTree returns the children of parentID, each with its own subtree
+func buildCategoryTree(categories []entities.Category, parentID *uint) []entities.CategoryNode {
+	nodes := []entities.CategoryNode{}
+	for _, c := range categories {
+		if !sameParent(c.ParentID, parentID) {
+			continue
+		}
+		id := c.ID
+		nodes = append(nodes, entities.CategoryNode{Category: c, Children: buildCategoryTree(categories, &id)})
+	}
+	return nodes
+}
+
+// categoryWithDescendants returns the ID of root and of every category below it
+func categoryWithDescendants(categories []entities.Category, root uint) []uint {
+	ids := []uint{root}
+	for i := 0; i < len(ids); i++ {
+		for _, c := range categories {
+			if c.ParentID != nil && *c.ParentID == ids[i] {
+				ids = append(ids, c.ID)
+			}
+		}
+	}
+	return ids
+}
+
+func findCategory(categories []entities.Category, slug string) *entities.Category {
+	for i := range categories {
+		if categories[i].Slug == slug {
+			return &categories[i]
+		}
+	}
+	return nil
+}
+
+func sameParent(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// postTags turns tag names into tags, names with the same slug are kept once
+func postTags(names []string) ([]entities.Tag, error) {
+	tags := make([]entities.Tag, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := commons.SlugifyName(name)
+		if slug == "" {
+			return nil, commons.ErrInvalidTagName
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		tags = append(tags, entities.Tag{Name: name, Slug: slug})
+	}
+	return tags, nil
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	categoryMocks "app/internal/repositories/category/mocks"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTaxonomyUsecase_GetCategories(t *testing.T) {
+	mockCategoryRepo := new(categoryMocks.CategoryRepository)
+	backend := uint(1)
+	mockCategoryRepo.On("GetAllCategories", mock.Anything).Return([]entities.Category{
+		{ID: 3, Name: "Databases", Slug: "databases", ParentID: &backend},
+		{ID: 1, Name: "Backend", Slug: "backend"},
+		{ID: 2, Name: "Frontend", Slug: "frontend"},
+	}, nil)
+
+	u := NewTaxonomyUsecase(nil, mockCategoryRepo, time.Second*2)
+	got, err := u.GetCategories(context.TODO())
+	if err != nil {
+		t.Fatalf("TaxonomyUsecase.GetCategories() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Slug != "backend" || len(got[0].Children) != 1 || got[0].Children[0].Slug != "databases" || len(got[1].Children) != 0 {
+		t.Errorf("TaxonomyUsecase.GetCategories() = %+v", got)
+	}
+	mockCategoryRepo.AssertExpectations(t)
+}
+
+func TestTaxonomyUsecase_CreateCategory(t *testing.T) {
+	mockCategoryRepo := new(categoryMocks.CategoryRepository)
+	timeout := time.Second * 2
+	admin := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1, Role: commons.RoleAdmin})
+	parent := uint(1)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     *entities.CreateCategoryRequest
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "subcategory",
+			ctx:  admin,
+			req:  &entities.CreateCategoryRequest{Name: "Data Bases", ParentID: &parent},
+			mock: func() {
+				mockCategoryRepo.On("GetCategoryBySlug", mock.Anything, "data-bases").Return(entities.Category{}, commons.ErrNotFound)
+				mockCategoryRepo.On("GetCategoryByID", mock.Anything, uint(1)).Return(entities.Category{ID: 1}, nil)
+				mockCategoryRepo.On("CreateCategory", mock.Anything, &entities.Category{Name: "Data Bases", Slug: "data-bases", ParentID: &parent}).Return(nil)
+			},
+		},
+		{
+			name:    "existing slug",
+			ctx:     admin,
+			req:     &entities.CreateCategoryRequest{Name: "Backend"},
+			wantErr: commons.ErrCategoryExists,
+			mock: func() {
+				mockCategoryRepo.On("GetCategoryBySlug", mock.Anything, "backend").Return(entities.Category{ID: 1}, nil)
+			},
+		},
+		{
+			name:    "unknown parent",
+			ctx:     admin,
+			req:     &entities.CreateCategoryRequest{Name: "Databases", ParentID: &parent},
+			wantErr: commons.ErrUnknownCategory,
+			mock: func() {
+				mockCategoryRepo.On("GetCategoryBySlug", mock.Anything, "databases").Return(entities.Category{}, commons.ErrNotFound)
+				mockCategoryRepo.On("GetCategoryByID", mock.Anything, uint(1)).Return(entities.Category{}, commons.ErrNotFound)
+			},
+		},
+		{
+			name:    "not an admin",
+			ctx:     commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 2, Role: commons.RoleUser}),
+			req:     &entities.CreateCategoryRequest{Name: "Backend"},
+			wantErr: commons.ErrForbidden,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCategoryRepo.ExpectedCalls = nil
+			tt.mock()
+
+			u := NewTaxonomyUsecase(nil, mockCategoryRepo, timeout)
+			if _, err := u.CreateCategory(tt.ctx, tt.req); err != tt.wantErr {
+				t.Errorf("TaxonomyUsecase.CreateCategory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockCategoryRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	recoveryCodeRepositories "app/internal/repositories/recoverycode"
+	userRepositories "app/internal/repositories/user"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	loginChallengePurpose = "login_2fa"
+	recoveryCodeCount     = 10
+)
+
+// TwoFactorUsecase manages TOTP enrollment and checks the second factor during login
+type TwoFactorUsecase interface {
+	Enroll(ctx context.Context) (entities.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, req *entities.TwoFactorCodeRequest) (entities.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, req *entities.TwoFactorCodeRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, req *entities.TwoFactorCodeRequest) (entities.RecoveryCodesResponse, error)
+	// Challenge issues the token a user with 2FA gets after the password step
+	Challenge(user entities.User) (entities.LoginResponse, error)
+	// ParseChallenge returns the user a challenge token was issued to
+	ParseChallenge(ctx context.Context, token string) (entities.User, error)
+	// Verify accepts a TOTP code or an unused recovery code of the user
+	Verify(ctx context.Context, user entities.User, code string) error
+}
+
+type TwoFactorConfig struct {
+	// Issuer is the account name shown in authenticator apps
+	Issuer string
+	// ChallengeDuration is how long the second step of a login may take
+	ChallengeDuration time.Duration
+}
+
+type twoFactorUsecase struct {
+	userRepo       userRepositories.UserRepository
+	recoveryRepo   recoveryCodeRepositories.RecoveryCodeRepository
+	guard          LoginGuard
+	jwtConfig      commons.ConfigJWT
+	config         TwoFactorConfig
+	contextTimeout time.Duration
+}
+
+func NewTwoFactorUsecase(user userRepositories.UserRepository, recovery recoveryCodeRepositories.RecoveryCodeRepository, guard LoginGuard, jwtConfig commons.ConfigJWT, config TwoFactorConfig, timeout time.Duration) TwoFactorUsecase {
+	return &twoFactorUsecase{
+		userRepo:       user,
+		recoveryRepo:   recovery,
+		guard:          guard,
+		jwtConfig:      jwtConfig,
+		config:         config,
+		contextTimeout: timeout,
+	}
+}
+
+// Enroll stores a new pending secret, 2FA is only enabled once a code from it is confirmed
+func (u *twoFactorUsecase) Enroll(ctx context.Context) (entities.TwoFactorEnrollment, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	user, err := u.currentUser(ctx)
+	if err != nil {
+		return entities.TwoFactorEnrollment{}, err
+	}
+	if user.TwoFactorEnabled {
+		return entities.TwoFactorEnrollment{}, commons.ErrTwoFactorEnabled
+	}
+
+	secret, err := commons.GenerateTOTPSecret()
+	if err != nil {
+		return entities.TwoFactorEnrollment{}, err
+	}
+	user.TOTPSecret = secret
+	if err := u.userRepo.UpdateUser(ctx, user); err != nil {
+		return entities.TwoFactorEnrollment{}, err
+	}
+
+	return entities.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    commons.TOTPURI(u.config.Issuer, user.Email, secret),
+	}, nil
+}
+
+func (u *twoFactorUsecase) Confirm(ctx context.Context, req *entities.TwoFactorCodeRequest) (entities.RecoveryCodesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.RecoveryCodesResponse{}, err
+	}
+
+	user, err := u.currentUser(ctx)
+	if err != nil {
+		return entities.RecoveryCodesResponse{}, err
+	}
+	if user.TwoFactorEnabled {
+		return entities.RecoveryCodesResponse{}, commons.ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return entities.RecoveryCodesResponse{}, commons.ErrTwoFactorNotEnrolled
+	}
+
+	var step int64
+	err = u.checkCode(ctx, user, req.IP, func() error {
+		step, err = u.verifyTOTP(ctx, user, normalizeTwoFactorCode(req.Code))
+		return err
+	})
+	if err != nil {
+		return entities.RecoveryCodesResponse{}, err
+	}
+
+	user.TOTPLastUsedStep = step
+	user.TwoFactorEnabled = true
+	if err := u.userRepo.UpdateUser(ctx, user); err != nil {
+		return entities.RecoveryCodesResponse{}, err
+	}
+
+	return u.replaceRecoveryCodes(ctx, user.ID)
+}
+
+func (u *twoFactorUsecase) Disable(ctx context.Context, req *entities.TwoFactorCodeRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := u.currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return commons.ErrTwoFactorNotEnabled
+	}
+	if err := u.checkCode(ctx, user, req.IP, func() error { return u.Verify(ctx, user, req.Code) }); err != nil {
+		return err
+	}
+
+	user.TwoFactorEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastUsedStep = 0
+	if err := u.userRepo.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+
+	return u.recoveryRepo.DeleteByUserID(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes invalidates the remaining recovery codes and returns a new set
+func (u *twoFactorUsecase) RegenerateRecoveryCodes(ctx context.Context, req *entities.TwoFactorCodeRequest) (entities.RecoveryCodesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.RecoveryCodesResponse{}, err
+	}
+
+	user, err := u.currentUser(ctx)
+	if err != nil {
+		return entities.RecoveryCodesResponse{}, err
+	}
+	if !user.TwoFactorEnabled {
+		return entities.RecoveryCodesResponse{}, commons.ErrTwoFactorNotEnabled
+	}
+	if err := u.checkCode(ctx, user, req.IP, func() error { return u.Verify(ctx, user, req.Code) }); err != nil {
+		return entities.RecoveryCodesResponse{}, err
+	}
+
+	return u.replaceRecoveryCodes(ctx, user.ID)
+}
+
+func (u *twoFactorUsecase) Challenge(user entities.User) (entities.LoginResponse, error) {
+	token, err := u.jwtConfig.GeneratePurposeJWT(loginChallengePurpose, strconv.FormatUint(uint64(user.ID), 10), u.config.ChallengeDuration)
+	if err != nil {
+		return entities.LoginResponse{}, err
+	}
+
+	return entities.LoginResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     token,
+		ChallengeExpiresIn: int64(u.config.ChallengeDuration.Seconds()),
+	}, nil
+}
+
+func (u *twoFactorUsecase) ParseChallenge(ctx context.Context, token string) (entities.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	subject, err := u.jwtConfig.ParsePurposeJWT(loginChallengePurpose, token)
+	if err != nil {
+		return entities.User{}, commons.ErrInvalidChallenge
+	}
+	id, err := strconv.ParseUint(subject, 10, 32)
+	if err != nil {
+		return entities.User{}, commons.ErrInvalidChallenge
+	}
+
+	user, err := u.userRepo.FindByID(ctx, uint(id))
+	if err != nil {
+		if err == commons.ErrNotFound {
+			return entities.User{}, commons.ErrInvalidChallenge
+		}
+		return entities.User{}, err
+	}
+	// 2FA may have been turned off since the challenge was issued
+	if !user.TwoFactorEnabled {
+		return entities.User{}, commons.ErrInvalidChallenge
+	}
+	return user, nil
+}
+
+func (u *twoFactorUsecase) Verify(ctx context.Context, user entities.User, code string) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	code = normalizeTwoFactorCode(code)
+	if isTOTPCode(code) {
+		_, err := u.verifyTOTP(ctx, user, code)
+		return err
+	}
+
+	return u.recoveryRepo.UseCode(ctx, user.ID, commons.HashToken(code))
+}
+
+// checkCode runs check, counting a wrong code against the same limits as a failed login so
+// codes can not be guessed with a stolen access token
+func (u *twoFactorUsecase) checkCode(ctx context.Context, user entities.User, ip string, check func() error) error {
+	if err := u.guard.Check(ctx, user.Email, ip); err != nil {
+		return err
+	}
+	if err := check(); err != nil {
+		if err == commons.ErrInvalidTwoFactorCode {
+			if err := u.guard.Failed(ctx, user.Email, ip, &user.ID, "wrong_2fa_code"); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// verifyTOTP checks the code and claims its time step, so the same code is not accepted twice
+func (u *twoFactorUsecase) verifyTOTP(ctx context.Context, user entities.User, code string) (int64, error) {
+	step, ok := commons.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return 0, commons.ErrInvalidTwoFactorCode
+	}
+	if err := u.userRepo.ClaimTOTPStep(ctx, user.ID, step); err != nil {
+		return 0, err
+	}
+	return step, nil
+}
+
+func (u *twoFactorUsecase) replaceRecoveryCodes(ctx context.Context, userID uint) (entities.RecoveryCodesResponse, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return entities.RecoveryCodesResponse{}, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, commons.HashToken(normalizeTwoFactorCode(code)))
+	}
+
+	if err := u.recoveryRepo.ReplaceCodes(ctx, userID, hashes); err != nil {
+		return entities.RecoveryCodesResponse{}, err
+	}
+	return entities.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (u *twoFactorUsecase) currentUser(ctx context.Context) (entities.User, error) {
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return entities.User{}, commons.ErrUnauthorized
+	}
+	return u.userRepo.FindByID(ctx, principal.UserID)
+}
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx for readability
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeTwoFactorCode drops the separators users type or copy along with a code
+func normalizeTwoFactorCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
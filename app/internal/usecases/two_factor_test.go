@@ -0,0 +1,176 @@
+package usecases
+
+import (
+	"app/internal/commons"
+	"app/internal/entities"
+	"app/internal/repositories/loginattempt"
+	recoveryCodeMocks "app/internal/repositories/recoverycode/mocks"
+	"app/internal/repositories/user/mocks"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTwoFactorUsecase_Confirm(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockRecoveryRepo := new(recoveryCodeMocks.RecoveryCodeRepository)
+	mockJWTConfig := commons.ConfigJWT{SecretJWT: "secret"}
+	timeout := time.Second * 2
+
+	secret, _ := commons.GenerateTOTPSecret()
+	validCode, _ := commons.TOTPCode(secret, commons.TOTPStep(time.Now()))
+	pending := entities.User{ID: 1, Email: "john@example.com", TOTPSecret: secret}
+
+	tests := []struct {
+		name      string
+		code      string
+		wantCodes int
+		wantErr   error
+		mock      func()
+	}{
+		{
+			name:      "success",
+			code:      validCode,
+			wantCodes: recoveryCodeCount,
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(pending, nil)
+				mockRepo.On("ClaimTOTPStep", mock.Anything, uint(1), mock.AnythingOfType("int64")).Return(nil)
+				mockRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u entities.User) bool {
+					return u.TwoFactorEnabled && u.TOTPSecret == secret && u.TOTPLastUsedStep > 0
+				})).Return(nil)
+				mockRecoveryRepo.On("ReplaceCodes", mock.Anything, uint(1), mock.MatchedBy(func(hashes []string) bool {
+					return len(hashes) == recoveryCodeCount
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "wrong code",
+			code:    "000000",
+			wantErr: commons.ErrInvalidTwoFactorCode,
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(pending, nil)
+			},
+		},
+		{
+			name:    "replayed code",
+			code:    validCode,
+			wantErr: commons.ErrInvalidTwoFactorCode,
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(pending, nil)
+				mockRepo.On("ClaimTOTPStep", mock.Anything, uint(1), mock.AnythingOfType("int64")).Return(commons.ErrInvalidTwoFactorCode)
+			},
+		},
+		{
+			name:    "not enrolled",
+			code:    validCode,
+			wantErr: commons.ErrTwoFactorNotEnrolled,
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1}, nil)
+			},
+		},
+		{
+			name:    "already enabled",
+			code:    validCode,
+			wantErr: commons.ErrTwoFactorEnabled,
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, TOTPSecret: secret, TwoFactorEnabled: true}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRecoveryRepo.ExpectedCalls = nil
+
+			tt.mock()
+			guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{})
+			u := NewTwoFactorUsecase(mockRepo, mockRecoveryRepo, guard, mockJWTConfig, TwoFactorConfig{Issuer: "Blog"}, timeout)
+			ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+			got, err := u.Confirm(ctx, &entities.TwoFactorCodeRequest{Code: tt.code})
+			if err != tt.wantErr {
+				t.Errorf("TwoFactorUsecase.Confirm() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got.RecoveryCodes) != tt.wantCodes {
+				t.Errorf("TwoFactorUsecase.Confirm() returned %d recovery codes, want %d", len(got.RecoveryCodes), tt.wantCodes)
+			}
+			mockRepo.AssertExpectations(t)
+			mockRecoveryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTwoFactorUsecase_Verify(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockRecoveryRepo := new(recoveryCodeMocks.RecoveryCodeRepository)
+	timeout := time.Second * 2
+
+	user := entities.User{ID: 1, TwoFactorEnabled: true}
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "recovery code is normalized",
+			code: " ABCDE-fghij ",
+			mock: func() {
+				mockRecoveryRepo.On("UseCode", mock.Anything, uint(1), commons.HashToken("abcdefghij")).Return(nil)
+			},
+		},
+		{
+			name:    "used recovery code",
+			code:    "abcde-fghij",
+			wantErr: commons.ErrInvalidTwoFactorCode,
+			mock: func() {
+				mockRecoveryRepo.On("UseCode", mock.Anything, uint(1), commons.HashToken("abcdefghij")).Return(commons.ErrInvalidTwoFactorCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRecoveryRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewTwoFactorUsecase(mockRepo, mockRecoveryRepo, nil, commons.ConfigJWT{}, TwoFactorConfig{}, timeout)
+			if err := u.Verify(context.TODO(), user, tt.code); err != tt.wantErr {
+				t.Errorf("TwoFactorUsecase.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mockRecoveryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTwoFactorUsecase_DisableLockout(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockRecoveryRepo := new(recoveryCodeMocks.RecoveryCodeRepository)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Email: "john@example.com", TwoFactorEnabled: true}, nil)
+	mockRecoveryRepo.On("UseCode", mock.Anything, uint(1), mock.Anything).Return(commons.ErrInvalidTwoFactorCode)
+
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{
+		MaxFailures:      3,
+		MaxFailuresPerIP: 50,
+		LockoutDuration:  time.Minute,
+	})
+	u := NewTwoFactorUsecase(mockRepo, mockRecoveryRepo, guard, commons.ConfigJWT{}, TwoFactorConfig{}, time.Second*2)
+
+	for i := 0; i < 3; i++ {
+		if err := u.Disable(ctx, &entities.TwoFactorCodeRequest{Code: "abcde-fghij", IP: "10.0.0.1"}); err != commons.ErrInvalidTwoFactorCode {
+			t.Fatalf("TwoFactorUsecase.Disable() attempt %d error = %v, wantErr %v", i+1, err, commons.ErrInvalidTwoFactorCode)
+		}
+	}
+	// Once locked, codes are no longer checked at all
+	if err := u.Disable(ctx, &entities.TwoFactorCodeRequest{Code: "abcde-fghij", IP: "10.0.0.1"}); err != commons.ErrAccountLocked {
+		t.Errorf("TwoFactorUsecase.Disable() error = %v, wantErr %v", err, commons.ErrAccountLocked)
+	}
+	mockRecoveryRepo.AssertNumberOfCalls(t, "UseCode", 3)
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+}
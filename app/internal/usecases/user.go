@@ -3,28 +3,48 @@ package usecases
 import (
 	"app/internal/commons"
 	"app/internal/entities"
+	sessionRepositories "app/internal/repositories/session"
 	repositories "app/internal/repositories/user"
 	"context"
+	"log"
 	"time"
 
 	"github.com/go-playground/validator/v10"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUsecase interface {
 	Register(ctx context.Context, req *entities.UserRegisterRequest) (entities.User, error)
-	Login(ctx context.Context, req *entities.UserLoginRequest) (string, error)
+	Login(ctx context.Context, req *entities.UserLoginRequest) (entities.LoginResponse, error)
+	LoginTwoFactor(ctx context.Context, req *entities.TwoFactorLoginRequest) (entities.TokenResponse, error)
+	LoginExternal(ctx context.Context, user entities.User) (entities.LoginResponse, error)
+	RefreshToken(ctx context.Context, req *entities.RefreshTokenRequest) (entities.TokenResponse, error)
+	Logout(ctx context.Context) error
+	LogoutAll(ctx context.Context) error
+	ValidateSession(ctx context.Context, sessionID uint) error
+	UpdatePassword(ctx context.Context, req *entities.UserUpdatePasswordRequest) (entities.TokenResponse, error)
+	UpdateRole(ctx context.Context, req *entities.UpdateRoleRequest) (entities.User, error)
+	Unlock(ctx context.Context, id uint) error
 }
 
 type userUsecase struct {
 	repo           repositories.UserRepository
+	sessionRepo    sessionRepositories.SessionRepository
+	verification   EmailVerificationUsecase
+	guard          LoginGuard
+	twoFactor      TwoFactorUsecase
+	hasher         commons.PasswordHasher
 	jwtConfig      commons.ConfigJWT
 	contextTimeout time.Duration
 }
 
-func NewUserUsecase(repo repositories.UserRepository, jwtConfig commons.ConfigJWT, timeout time.Duration) UserUsecase {
+func NewUserUsecase(repo repositories.UserRepository, sessionRepo sessionRepositories.SessionRepository, verification EmailVerificationUsecase, guard LoginGuard, twoFactor TwoFactorUsecase, hasher commons.PasswordHasher, jwtConfig commons.ConfigJWT, timeout time.Duration) UserUsecase {
 	return &userUsecase{
 		repo:           repo,
+		sessionRepo:    sessionRepo,
+		verification:   verification,
+		guard:          guard,
+		twoFactor:      twoFactor,
+		hasher:         hasher,
 		jwtConfig:      jwtConfig,
 		contextTimeout: timeout,
 	}
@@ -32,12 +52,18 @@ func NewUserUsecase(repo repositories.UserRepository, jwtConfig commons.ConfigJW
 
 // mocked functions
 var (
-	hashPassword = func(password string) string {
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-		return string(hashedPassword)
+	generateJWT = func(u *userUsecase, user entities.User, sessionID uint) (string, error) {
+		return u.jwtConfig.GenerateJWT(commons.Principal{
+			UserID:        user.ID,
+			Name:          user.Name,
+			Email:         user.Email,
+			Role:          user.Role,
+			EmailVerified: user.EmailVerified,
+			SessionID:     sessionID,
+		})
 	}
-	generateJWT = func(u *userUsecase, email string) (string, error) {
-		return u.jwtConfig.GenerateJWT(email)
+	generateRefreshToken = func() (string, error) {
+		return commons.GenerateRandomToken(32)
 	}
 )
 
@@ -57,12 +83,16 @@ func (u *userUsecase) Register(ctx context.Context, req *entities.UserRegisterRe
 	}
 
 	// Hash password
-	hashedPassword := hashPassword(req.Password)
+	hashedPassword, err := u.hasher.Hash(req.Password)
+	if err != nil {
+		return entities.User{}, err
+	}
 
 	user := entities.User{
 		Name:         req.Name,
 		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
+		Role:         commons.RoleUser,
 	}
 
 	err = u.repo.CreateUser(ctx, user)
@@ -70,32 +100,341 @@ func (u *userUsecase) Register(ctx context.Context, req *entities.UserRegisterRe
 		return entities.User{}, err
 	}
 
+	// A failed email should not fail the registration, the user can ask for a new link
+	if err := u.verification.SendVerification(ctx, user); err != nil {
+		log.Printf("failed to send verification email: %v", err)
+	}
+
 	return user, nil
 }
 
-func (u *userUsecase) Login(ctx context.Context, req *entities.UserLoginRequest) (string, error) {
+func (u *userUsecase) Login(ctx context.Context, req *entities.UserLoginRequest) (entities.LoginResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
 	validator := validator.New()
 	if err := validator.Struct(req); err != nil {
-		return "", err
+		return entities.LoginResponse{}, err
+	}
+
+	if err := u.guard.Check(ctx, req.Email, req.IP); err != nil {
+		return entities.LoginResponse{}, err
 	}
 
 	user, err := u.repo.FindByEmail(ctx, req.Email)
 	if err != nil {
-		return "", commons.ErrInvalidCredentials
+		if err == commons.ErrNotFound {
+			if err := u.guard.Failed(ctx, req.Email, req.IP, nil, "unknown_email"); err != nil {
+				return entities.LoginResponse{}, err
+			}
+		}
+		return entities.LoginResponse{}, commons.ErrInvalidCredentials
+	}
+
+	// Accounts created through an identity provider have no password until one is reset
+	match, needsRehash := false, false
+	if user.PasswordHash != "" {
+		match, needsRehash, err = u.hasher.Verify(req.Password, user.PasswordHash)
+		if err != nil {
+			log.Printf("failed to verify password of user %d: %v", user.ID, err)
+		}
+	}
+	if !match {
+		if err := u.guard.Failed(ctx, req.Email, req.IP, &user.ID, "wrong_password"); err != nil {
+			return entities.LoginResponse{}, err
+		}
+		return entities.LoginResponse{}, commons.ErrInvalidCredentials
+	}
+
+	// The plain password is only known at login, so this is when old hashes get upgraded
+	if needsRehash {
+		u.rehashPassword(ctx, &user, req.Password)
+	}
+
+	// The failure counter is kept until the second factor is passed as well, otherwise
+	// the password step could be repeated to get unlimited guesses at the code
+	if user.TwoFactorEnabled {
+		return u.twoFactor.Challenge(user)
+	}
+
+	if err := u.guard.Succeeded(ctx, req.Email, req.IP, user.ID); err != nil {
+		return entities.LoginResponse{}, err
+	}
+
+	token, err := u.startSession(ctx, user)
+	if err != nil {
+		return entities.LoginResponse{}, err
+	}
+	return entities.LoginResponse{TokenResponse: &token}, nil
+}
+
+// LoginTwoFactor completes the login of a user with 2FA using the challenge from Login
+func (u *userUsecase) LoginTwoFactor(ctx context.Context, req *entities.TwoFactorLoginRequest) (entities.TokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	user, err := u.twoFactor.ParseChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	if err := u.guard.Check(ctx, user.Email, req.IP); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	if err := u.twoFactor.Verify(ctx, user, req.Code); err != nil {
+		if err == commons.ErrInvalidTwoFactorCode {
+			if err := u.guard.Failed(ctx, user.Email, req.IP, &user.ID, "wrong_2fa_code"); err != nil {
+				return entities.TokenResponse{}, err
+			}
+		}
+		return entities.TokenResponse{}, err
+	}
+
+	if err := u.guard.Succeeded(ctx, user.Email, req.IP, user.ID); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	return u.startSession(ctx, user)
+}
+
+// LoginExternal issues tokens for a user that was authenticated by an identity provider.
+// A local second factor is still required when the user enabled one.
+func (u *userUsecase) LoginExternal(ctx context.Context, user entities.User) (entities.LoginResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if user.TwoFactorEnabled {
+		return u.twoFactor.Challenge(user)
+	}
+
+	token, err := u.startSession(ctx, user)
+	if err != nil {
+		return entities.LoginResponse{}, err
+	}
+	return entities.LoginResponse{TokenResponse: &token}, nil
+}
+
+func (u *userUsecase) RefreshToken(ctx context.Context, req *entities.RefreshTokenRequest) (entities.TokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	oldHash := commons.HashToken(req.RefreshToken)
+	session, err := u.sessionRepo.FindByRefreshTokenHash(ctx, oldHash)
+	if err != nil {
+		if err == commons.ErrNotFound {
+			return entities.TokenResponse{}, commons.ErrUnauthorized
+		}
+		return entities.TokenResponse{}, err
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return entities.TokenResponse{}, commons.ErrSessionRevoked
 	}
 
-	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
-		return "", commons.ErrInvalidCredentials
+	user, err := u.repo.FindByID(ctx, session.UserID)
+	if err != nil {
+		return entities.TokenResponse{}, commons.ErrUnauthorized
+	}
+
+	// Rotate the refresh token, the presented one can not be used again
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return entities.TokenResponse{}, err
 	}
+	expiresAt := time.Now().Add(u.jwtConfig.RefreshTokenTTL())
+	if err := u.sessionRepo.RotateRefreshToken(ctx, session.ID, oldHash, commons.HashToken(refreshToken), expiresAt); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	return u.issueTokens(user, session.ID, refreshToken)
+}
+
+func (u *userUsecase) Logout(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return commons.ErrUnauthorized
+	}
+
+	return u.sessionRepo.RevokeSession(ctx, principal.SessionID)
+}
+
+func (u *userUsecase) LogoutAll(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return commons.ErrUnauthorized
+	}
+
+	return u.sessionRepo.RevokeAllByUserID(ctx, principal.UserID)
+}
+
+// ValidateSession is used by the JWT middleware to reject access tokens of revoked sessions
+func (u *userUsecase) ValidateSession(ctx context.Context, sessionID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	session, err := u.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if err == commons.ErrNotFound {
+			return commons.ErrSessionRevoked
+		}
+		return err
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return commons.ErrSessionRevoked
+	}
+	return nil
+}
+
+func (u *userUsecase) UpdatePassword(ctx context.Context, req *entities.UserUpdatePasswordRequest) (entities.TokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	principal, ok := commons.PrincipalFromContext(ctx)
+	if !ok {
+		return entities.TokenResponse{}, commons.ErrUnauthorized
+	}
+	user, err := u.repo.FindByID(ctx, principal.UserID)
+	if err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	// Guesses of the current password with a stolen access token count against the same
+	// limits as failed logins
+	if err := u.guard.Check(ctx, user.Email, req.IP); err != nil {
+		return entities.TokenResponse{}, err
+	}
+	if match, _, err := u.hasher.Verify(req.Password, user.PasswordHash); err != nil || !match {
+		if err := u.guard.Failed(ctx, user.Email, req.IP, &user.ID, "wrong_password_change"); err != nil {
+			return entities.TokenResponse{}, err
+		}
+		return entities.TokenResponse{}, commons.ErrInvalidCredentials
+	}
+
+	user.PasswordHash, err = u.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return entities.TokenResponse{}, err
+	}
+	if err := u.repo.UpdateUser(ctx, user); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	// Tokens issued with the old password must stop working, the caller gets a fresh pair
+	if err := u.sessionRepo.RevokeAllByUserID(ctx, user.ID); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	return u.startSession(ctx, user)
+}
+
+func (u *userUsecase) UpdateRole(ctx context.Context, req *entities.UpdateRoleRequest) (entities.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	validator := validator.New()
+	if err := validator.Struct(req); err != nil {
+		return entities.User{}, err
+	}
+
+	user, err := u.repo.FindByID(ctx, req.UserID)
+	if err != nil {
+		return entities.User{}, err
+	}
+	if user.Role == req.Role {
+		return user, nil
+	}
+
+	user.Role = req.Role
+	if err := u.repo.UpdateUser(ctx, user); err != nil {
+		return entities.User{}, err
+	}
+
+	// The role is carried in the access token, so existing sessions must log in again to pick it up
+	if err := u.sessionRepo.RevokeAllByUserID(ctx, user.ID); err != nil {
+		return entities.User{}, err
+	}
+
+	return user, nil
+}
+
+// Unlock lifts a login lockout of a user before it expires
+func (u *userUsecase) Unlock(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	user, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return u.guard.Unlock(ctx, user.Email)
+}
+
+// rehashPassword replaces the stored hash with one using the current algorithm and cost.
+// A failure is only logged, the old hash keeps working and is retried on the next login.
+func (u *userUsecase) rehashPassword(ctx context.Context, user *entities.User, password string) {
+	hash, err := u.hasher.Hash(password)
+	if err != nil {
+		log.Printf("failed to rehash password of user %d: %v", user.ID, err)
+		return
+	}
+	updated := *user
+	updated.PasswordHash = hash
+	if err := u.repo.UpdateUser(ctx, updated); err != nil {
+		log.Printf("failed to rehash password of user %d: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = hash
+}
+
+// startSession creates a new server-side session for the user and issues its tokens
+func (u *userUsecase) startSession(ctx context.Context, user entities.User) (entities.TokenResponse, error) {
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return entities.TokenResponse{}, err
+	}
+	session := &entities.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: commons.HashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(u.jwtConfig.RefreshTokenTTL()),
+	}
+	if err := u.sessionRepo.CreateSession(ctx, session); err != nil {
+		return entities.TokenResponse{}, err
+	}
+
+	return u.issueTokens(user, session.ID, refreshToken)
+}
 
-	// Generate JWT token
-	token, err := generateJWT(u, req.Email)
+func (u *userUsecase) issueTokens(user entities.User, sessionID uint, refreshToken string) (entities.TokenResponse, error) {
+	accessToken, err := generateJWT(u, user, sessionID)
 	if err != nil {
-		return "", err
+		return entities.TokenResponse{}, err
 	}
 
-	return token, nil
+	return entities.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(u.jwtConfig.AccessTokenTTL().Seconds()),
+	}, nil
 }
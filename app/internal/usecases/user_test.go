@@ -3,6 +3,10 @@ package usecases
 import (
 	"app/internal/commons"
 	"app/internal/entities"
+	"app/internal/mailer"
+	"app/internal/repositories/loginattempt"
+	recoveryCodeMocks "app/internal/repositories/recoverycode/mocks"
+	sessionMocks "app/internal/repositories/session/mocks"
 	"app/internal/repositories/user/mocks"
 	"context"
 	"errors"
@@ -14,21 +18,24 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// testHasher uses the smallest Argon2id cost so the tests stay fast
+var testHasher = commons.NewPasswordHasher(commons.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+
 func TestUserUsecase_Register(t *testing.T) {
 	type args struct {
 		req *entities.UserRegisterRequest
 	}
 
 	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
 	mockJWTConfig := commons.ConfigJWT{
 		SecretJWT:       "secret",
 		ExpiresDuration: 1,
 	}
 	timeout := time.Second * 2
-
-	hashPassword = func(password string) string {
-		return string(password)
-	}
+	verification := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), mockJWTConfig, EmailVerificationConfig{ExpiresDuration: 1}, timeout)
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{})
+	twoFactor := NewTwoFactorUsecase(mockRepo, new(recoveryCodeMocks.RecoveryCodeRepository), nil, mockJWTConfig, TwoFactorConfig{ChallengeDuration: time.Minute}, timeout)
 
 	tests := []struct {
 		name    string
@@ -47,9 +54,9 @@ func TestUserUsecase_Register(t *testing.T) {
 				},
 			},
 			want: entities.User{
-				Name:         "John Doe",
-				Email:        "john@example.com",
-				PasswordHash: hashPassword("password"),
+				Name:  "John Doe",
+				Email: "john@example.com",
+				Role:  commons.RoleUser,
 			},
 			wantErr: false,
 			mock: func() {
@@ -108,16 +115,24 @@ func TestUserUsecase_Register(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 
 			tt.mock()
-			u := NewUserUsecase(mockRepo, mockJWTConfig, timeout)
+			u := NewUserUsecase(mockRepo, mockSessionRepo, verification, guard, twoFactor, testHasher, mockJWTConfig, timeout)
 			got, err := u.Register(context.TODO(), tt.args.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UserUsecase.Register() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if !tt.wantErr {
+				// The salt makes every hash different, so only check that it verifies
+				if ok, _, _ := testHasher.Verify(tt.args.req.Password, got.PasswordHash); !ok {
+					t.Errorf("UserUsecase.Register() stored a hash that does not match the password")
+				}
+				got.PasswordHash = ""
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("UserUsecase.Register() = %v, want %v", got, tt.want)
 			}
 			mockRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -128,20 +143,28 @@ func TestUserUsecase_Login(t *testing.T) {
 	}
 
 	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
 	mockJWTConfig := commons.ConfigJWT{
-		SecretJWT:       "secret",
-		ExpiresDuration: 1,
+		SecretJWT:              "secret",
+		ExpiresDuration:        1,
+		RefreshExpiresDuration: 1,
 	}
 	timeout := time.Second * 2
+	verification := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), mockJWTConfig, EmailVerificationConfig{ExpiresDuration: 1}, timeout)
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{})
+	twoFactor := NewTwoFactorUsecase(mockRepo, new(recoveryCodeMocks.RecoveryCodeRepository), nil, mockJWTConfig, TwoFactorConfig{ChallengeDuration: time.Minute}, timeout)
 
-	generateJWT = func(u *userUsecase, email string) (string, error) {
+	generateJWT = func(u *userUsecase, user entities.User, sessionID uint) (string, error) {
 		return "some-jwt-token", nil
 	}
+	generateRefreshToken = func() (string, error) {
+		return "some-refresh-token", nil
+	}
 
 	tests := []struct {
 		name    string
 		args    args
-		want    string
+		want    entities.LoginResponse
 		wantErr bool
 		mock    func()
 	}{
@@ -153,15 +176,54 @@ func TestUserUsecase_Login(t *testing.T) {
 					Password: "password",
 				},
 			},
-			want:    "some-jwt-token",
+			want: entities.LoginResponse{TokenResponse: &entities.TokenResponse{
+				AccessToken:  "some-jwt-token",
+				RefreshToken: "some-refresh-token",
+				TokenType:    "Bearer",
+				ExpiresIn:    60,
+			}},
 			wantErr: false,
 			mock: func() {
-				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+				hashedPassword, _ := testHasher.Hash("password")
 				user := entities.User{
+					ID:           1,
+					Email:        "john@example.com",
+					PasswordHash: hashedPassword,
+				}
+				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(user, nil)
+				mockSessionRepo.On("CreateSession", mock.Anything, mock.MatchedBy(func(s *entities.Session) bool {
+					return s.UserID == 1 && s.RefreshTokenHash == commons.HashToken("some-refresh-token")
+				})).Return(nil)
+			},
+		},
+		{
+			name: "success upgrades a bcrypt hash",
+			args: args{
+				req: &entities.UserLoginRequest{
+					Email:    "john@example.com",
+					Password: "password",
+				},
+			},
+			want: entities.LoginResponse{TokenResponse: &entities.TokenResponse{
+				AccessToken:  "some-jwt-token",
+				RefreshToken: "some-refresh-token",
+				TokenType:    "Bearer",
+				ExpiresIn:    60,
+			}},
+			wantErr: false,
+			mock: func() {
+				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+				user := entities.User{
+					ID:           1,
 					Email:        "john@example.com",
 					PasswordHash: string(hashedPassword),
 				}
 				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(user, nil)
+				mockRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u entities.User) bool {
+					ok, needsRehash, _ := testHasher.Verify("password", u.PasswordHash)
+					return u.ID == 1 && ok && !needsRehash
+				})).Return(nil)
+				mockSessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*entities.Session")).Return(nil)
 			},
 		},
 		{
@@ -172,7 +234,7 @@ func TestUserUsecase_Login(t *testing.T) {
 					Password: "wrongpassword",
 				},
 			},
-			want:    "",
+			want:    entities.LoginResponse{},
 			wantErr: true,
 			mock: func() {
 				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
@@ -191,7 +253,7 @@ func TestUserUsecase_Login(t *testing.T) {
 					Password: "password",
 				},
 			},
-			want:    "",
+			want:    entities.LoginResponse{},
 			wantErr: true,
 			mock: func() {
 				mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(entities.User{}, commons.ErrNotFound)
@@ -205,7 +267,7 @@ func TestUserUsecase_Login(t *testing.T) {
 					Password: "short",
 				},
 			},
-			want:    "",
+			want:    entities.LoginResponse{},
 			wantErr: true,
 			mock:    func() {},
 		},
@@ -214,9 +276,10 @@ func TestUserUsecase_Login(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
+			mockSessionRepo.ExpectedCalls = nil
 
 			tt.mock()
-			u := NewUserUsecase(mockRepo, mockJWTConfig, timeout)
+			u := NewUserUsecase(mockRepo, mockSessionRepo, verification, guard, twoFactor, testHasher, mockJWTConfig, timeout)
 			got, err := u.Login(context.TODO(), tt.args.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UserUsecase.Login() error = %v, wantErr %v", err, tt.wantErr)
@@ -226,6 +289,395 @@ func TestUserUsecase_Login(t *testing.T) {
 				t.Errorf("UserUsecase.Login() = %v, want %v", got, tt.want)
 			}
 			mockRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserUsecase_LoginTwoFactor(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	mockRecoveryRepo := new(recoveryCodeMocks.RecoveryCodeRepository)
+	mockJWTConfig := commons.ConfigJWT{
+		SecretJWT:              "secret",
+		ExpiresDuration:        1,
+		RefreshExpiresDuration: 1,
+	}
+	timeout := time.Second * 2
+	verification := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), mockJWTConfig, EmailVerificationConfig{ExpiresDuration: 1}, timeout)
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{MaxFailures: 5, MaxFailuresPerIP: 20, LockoutDuration: time.Minute})
+	twoFactor := NewTwoFactorUsecase(mockRepo, mockRecoveryRepo, nil, mockJWTConfig, TwoFactorConfig{ChallengeDuration: time.Minute}, timeout)
+	u := NewUserUsecase(mockRepo, mockSessionRepo, verification, guard, twoFactor, testHasher, mockJWTConfig, timeout)
+
+	generateJWT = func(u *userUsecase, user entities.User, sessionID uint) (string, error) {
+		return "some-jwt-token", nil
+	}
+	generateRefreshToken = func() (string, error) {
+		return "some-refresh-token", nil
+	}
+
+	secret, _ := commons.GenerateTOTPSecret()
+	hashedPassword, _ := testHasher.Hash("password")
+	user := entities.User{
+		ID:               1,
+		Email:            "john@example.com",
+		PasswordHash:     hashedPassword,
+		TOTPSecret:       secret,
+		TwoFactorEnabled: true,
+	}
+	mockRepo.On("FindByEmail", mock.Anything, "john@example.com").Return(user, nil)
+	mockRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+
+	// The password alone only yields a challenge
+	challenge, err := u.Login(context.TODO(), &entities.UserLoginRequest{Email: "john@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("UserUsecase.Login() error = %v", err)
+	}
+	if !challenge.TwoFactorRequired || challenge.ChallengeToken == "" || challenge.TokenResponse != nil {
+		t.Fatalf("UserUsecase.Login() = %+v, want a challenge without tokens", challenge)
+	}
+
+	if _, err := u.LoginTwoFactor(context.TODO(), &entities.TwoFactorLoginRequest{ChallengeToken: "some-jwt-token", Code: "123456"}); err != commons.ErrInvalidChallenge {
+		t.Errorf("UserUsecase.LoginTwoFactor() with an invalid challenge error = %v, want %v", err, commons.ErrInvalidChallenge)
+	}
+
+	mockRecoveryRepo.On("UseCode", mock.Anything, uint(1), commons.HashToken("abcdefghij")).Return(commons.ErrInvalidTwoFactorCode)
+	if _, err := u.LoginTwoFactor(context.TODO(), &entities.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: "abcde-fghij"}); err != commons.ErrInvalidTwoFactorCode {
+		t.Errorf("UserUsecase.LoginTwoFactor() with a wrong code error = %v, want %v", err, commons.ErrInvalidTwoFactorCode)
+	}
+
+	step := commons.TOTPStep(time.Now())
+	code, _ := commons.TOTPCode(secret, step)
+	mockRepo.On("ClaimTOTPStep", mock.Anything, uint(1), step).Return(nil)
+	mockSessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*entities.Session")).Return(nil)
+	got, err := u.LoginTwoFactor(context.TODO(), &entities.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+	if err != nil {
+		t.Fatalf("UserUsecase.LoginTwoFactor() error = %v", err)
+	}
+	if got.AccessToken != "some-jwt-token" || got.RefreshToken != "some-refresh-token" {
+		t.Errorf("UserUsecase.LoginTwoFactor() = %+v", got)
+	}
+	mockRepo.AssertExpectations(t)
+	mockSessionRepo.AssertExpectations(t)
+	mockRecoveryRepo.AssertExpectations(t)
+}
+
+func TestUserUsecase_RefreshToken(t *testing.T) {
+	type args struct {
+		req *entities.RefreshTokenRequest
+	}
+
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	mockJWTConfig := commons.ConfigJWT{
+		SecretJWT:              "secret",
+		ExpiresDuration:        1,
+		RefreshExpiresDuration: 1,
+	}
+	timeout := time.Second * 2
+	verification := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), mockJWTConfig, EmailVerificationConfig{ExpiresDuration: 1}, timeout)
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{})
+	twoFactor := NewTwoFactorUsecase(mockRepo, new(recoveryCodeMocks.RecoveryCodeRepository), nil, mockJWTConfig, TwoFactorConfig{ChallengeDuration: time.Minute}, timeout)
+
+	generateJWT = func(u *userUsecase, user entities.User, sessionID uint) (string, error) {
+		return "new-jwt-token", nil
+	}
+	generateRefreshToken = func() (string, error) {
+		return "new-refresh-token", nil
+	}
+
+	oldHash := commons.HashToken("old-refresh-token")
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		args    args
+		want    entities.TokenResponse
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success rotates refresh token",
+			args: args{req: &entities.RefreshTokenRequest{RefreshToken: "old-refresh-token"}},
+			want: entities.TokenResponse{
+				AccessToken:  "new-jwt-token",
+				RefreshToken: "new-refresh-token",
+				TokenType:    "Bearer",
+				ExpiresIn:    60,
+			},
+			mock: func() {
+				mockSessionRepo.On("FindByRefreshTokenHash", mock.Anything, oldHash).Return(entities.Session{
+					ID:        7,
+					UserID:    1,
+					ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Email: "john@example.com"}, nil)
+				mockSessionRepo.On("RotateRefreshToken", mock.Anything, uint(7), oldHash, commons.HashToken("new-refresh-token"), mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name:    "unknown or already rotated token",
+			args:    args{req: &entities.RefreshTokenRequest{RefreshToken: "old-refresh-token"}},
+			want:    entities.TokenResponse{},
+			wantErr: commons.ErrUnauthorized,
+			mock: func() {
+				mockSessionRepo.On("FindByRefreshTokenHash", mock.Anything, oldHash).Return(entities.Session{}, commons.ErrNotFound)
+			},
+		},
+		{
+			name:    "revoked session",
+			args:    args{req: &entities.RefreshTokenRequest{RefreshToken: "old-refresh-token"}},
+			want:    entities.TokenResponse{},
+			wantErr: commons.ErrSessionRevoked,
+			mock: func() {
+				mockSessionRepo.On("FindByRefreshTokenHash", mock.Anything, oldHash).Return(entities.Session{
+					ID:        7,
+					UserID:    1,
+					ExpiresAt: time.Now().Add(time.Hour),
+					RevokedAt: &revokedAt,
+				}, nil)
+			},
+		},
+		{
+			name:    "expired session",
+			args:    args{req: &entities.RefreshTokenRequest{RefreshToken: "old-refresh-token"}},
+			want:    entities.TokenResponse{},
+			wantErr: commons.ErrSessionRevoked,
+			mock: func() {
+				mockSessionRepo.On("FindByRefreshTokenHash", mock.Anything, oldHash).Return(entities.Session{
+					ID:        7,
+					UserID:    1,
+					ExpiresAt: time.Now().Add(-time.Hour),
+				}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockSessionRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewUserUsecase(mockRepo, mockSessionRepo, verification, guard, twoFactor, testHasher, mockJWTConfig, timeout)
+			got, err := u.RefreshToken(context.TODO(), tt.args.req)
+			if err != tt.wantErr {
+				t.Errorf("UserUsecase.RefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UserUsecase.RefreshToken() = %v, want %v", got, tt.want)
+			}
+			mockRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserUsecase_LogoutAll(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	mockJWTConfig := commons.ConfigJWT{SecretJWT: "secret"}
+	timeout := time.Second * 2
+	verification := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), mockJWTConfig, EmailVerificationConfig{ExpiresDuration: 1}, timeout)
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{})
+	twoFactor := NewTwoFactorUsecase(mockRepo, new(recoveryCodeMocks.RecoveryCodeRepository), nil, mockJWTConfig, TwoFactorConfig{ChallengeDuration: time.Minute}, timeout)
+
+	mockSessionRepo.On("RevokeAllByUserID", mock.Anything, uint(1)).Return(nil)
+
+	u := NewUserUsecase(mockRepo, mockSessionRepo, verification, guard, twoFactor, testHasher, mockJWTConfig, timeout)
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1, SessionID: 7})
+	if err := u.LogoutAll(ctx); err != nil {
+		t.Errorf("UserUsecase.LogoutAll() error = %v", err)
+	}
+	if err := u.LogoutAll(context.TODO()); err != commons.ErrUnauthorized {
+		t.Errorf("UserUsecase.LogoutAll() without session error = %v, want %v", err, commons.ErrUnauthorized)
+	}
+	mockSessionRepo.AssertExpectations(t)
+}
+
+func TestUserUsecase_UpdatePassword(t *testing.T) {
+	type args struct {
+		req *entities.UserUpdatePasswordRequest
+	}
+
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	mockJWTConfig := commons.ConfigJWT{
+		SecretJWT:              "secret",
+		ExpiresDuration:        1,
+		RefreshExpiresDuration: 1,
+	}
+	timeout := time.Second * 2
+	verification := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), mockJWTConfig, EmailVerificationConfig{ExpiresDuration: 1}, timeout)
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{})
+	twoFactor := NewTwoFactorUsecase(mockRepo, new(recoveryCodeMocks.RecoveryCodeRepository), nil, mockJWTConfig, TwoFactorConfig{ChallengeDuration: time.Minute}, timeout)
+
+	generateJWT = func(u *userUsecase, user entities.User, sessionID uint) (string, error) {
+		return "new-jwt-token", nil
+	}
+	generateRefreshToken = func() (string, error) {
+		return "new-refresh-token", nil
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	user := entities.User{
+		ID:           1,
+		Email:        "john@example.com",
+		PasswordHash: string(hashedPassword),
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    entities.TokenResponse
+		wantErr error
+		mock    func()
+	}{
+		{
+			name: "success revokes existing sessions",
+			args: args{req: &entities.UserUpdatePasswordRequest{Password: "password", NewPassword: "new-password"}},
+			want: entities.TokenResponse{
+				AccessToken:  "new-jwt-token",
+				RefreshToken: "new-refresh-token",
+				TokenType:    "Bearer",
+				ExpiresIn:    60,
+			},
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+				mockRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u entities.User) bool {
+					ok, _, _ := testHasher.Verify("new-password", u.PasswordHash)
+					return u.ID == 1 && ok
+				})).Return(nil)
+				mockSessionRepo.On("RevokeAllByUserID", mock.Anything, uint(1)).Return(nil)
+				mockSessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*entities.Session")).Return(nil)
+			},
+		},
+		{
+			name:    "wrong current password",
+			args:    args{req: &entities.UserUpdatePasswordRequest{Password: "wrongpassword", NewPassword: "new-password"}},
+			want:    entities.TokenResponse{},
+			wantErr: commons.ErrInvalidCredentials,
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockSessionRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewUserUsecase(mockRepo, mockSessionRepo, verification, guard, twoFactor, testHasher, mockJWTConfig, timeout)
+			ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1, Email: "john@example.com", SessionID: 7})
+			got, err := u.UpdatePassword(ctx, tt.args.req)
+			if err != tt.wantErr {
+				t.Errorf("UserUsecase.UpdatePassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UserUsecase.UpdatePassword() = %v, want %v", got, tt.want)
+			}
+			mockRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserUsecase_UpdatePasswordThrottled(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	hash, _ := testHasher.Hash("password")
+	ctx := commons.WithPrincipal(context.TODO(), commons.Principal{UserID: 1})
+
+	mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Email: "john@example.com", PasswordHash: hash}, nil)
+
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{
+		MaxFailures:      5,
+		MaxFailuresPerIP: 50,
+		LockoutDuration:  time.Minute,
+		BackoffBase:      time.Minute,
+		BackoffMax:       time.Minute,
+	})
+	u := NewUserUsecase(mockRepo, mockSessionRepo, nil, guard, nil, testHasher, commons.ConfigJWT{}, time.Second*2)
+
+	if _, err := u.UpdatePassword(ctx, &entities.UserUpdatePasswordRequest{Password: "guess-one", NewPassword: "new-password", IP: "10.0.0.1"}); err != commons.ErrInvalidCredentials {
+		t.Fatalf("UserUsecase.UpdatePassword() error = %v, wantErr %v", err, commons.ErrInvalidCredentials)
+	}
+	// The next guess has to wait out the backoff like a failed login
+	if _, err := u.UpdatePassword(ctx, &entities.UserUpdatePasswordRequest{Password: "password", NewPassword: "new-password", IP: "10.0.0.1"}); err != commons.ErrTooManyAttempts {
+		t.Errorf("UserUsecase.UpdatePassword() error = %v, wantErr %v", err, commons.ErrTooManyAttempts)
+	}
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	mockSessionRepo.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything)
+}
+
+func TestUserUsecase_UpdateRole(t *testing.T) {
+	type args struct {
+		req *entities.UpdateRoleRequest
+	}
+
+	mockRepo := new(mocks.UserRepository)
+	mockSessionRepo := new(sessionMocks.SessionRepository)
+	mockJWTConfig := commons.ConfigJWT{SecretJWT: "secret"}
+	timeout := time.Second * 2
+	verification := NewEmailVerificationUsecase(mockRepo, mailer.NewOutboxMailer(t.TempDir(), "noreply@example.com"), mockJWTConfig, EmailVerificationConfig{ExpiresDuration: 1}, timeout)
+	guard := NewLoginGuard(loginattempt.NewMemoryLoginAttemptRepository(), LoginGuardConfig{})
+	twoFactor := NewTwoFactorUsecase(mockRepo, new(recoveryCodeMocks.RecoveryCodeRepository), nil, mockJWTConfig, TwoFactorConfig{ChallengeDuration: time.Minute}, timeout)
+
+	tests := []struct {
+		name    string
+		args    args
+		want    entities.User
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "promote revokes existing sessions",
+			args: args{req: &entities.UpdateRoleRequest{UserID: 1, Role: commons.RoleAdmin}},
+			want: entities.User{ID: 1, Email: "john@example.com", Role: commons.RoleAdmin},
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Email: "john@example.com", Role: commons.RoleUser}, nil)
+				mockRepo.On("UpdateUser", mock.Anything, entities.User{ID: 1, Email: "john@example.com", Role: commons.RoleAdmin}).Return(nil)
+				mockSessionRepo.On("RevokeAllByUserID", mock.Anything, uint(1)).Return(nil)
+			},
+		},
+		{
+			name: "unchanged role",
+			args: args{req: &entities.UpdateRoleRequest{UserID: 1, Role: commons.RoleModerator}},
+			want: entities.User{ID: 1, Email: "john@example.com", Role: commons.RoleModerator},
+			mock: func() {
+				mockRepo.On("FindByID", mock.Anything, uint(1)).Return(entities.User{ID: 1, Email: "john@example.com", Role: commons.RoleModerator}, nil)
+			},
+		},
+		{
+			name:    "unknown role",
+			args:    args{req: &entities.UpdateRoleRequest{UserID: 1, Role: "superuser"}},
+			want:    entities.User{},
+			wantErr: true,
+			mock:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockSessionRepo.ExpectedCalls = nil
+
+			tt.mock()
+			u := NewUserUsecase(mockRepo, mockSessionRepo, verification, guard, twoFactor, testHasher, mockJWTConfig, timeout)
+			got, err := u.UpdateRole(context.TODO(), tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UserUsecase.UpdateRole() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UserUsecase.UpdateRole() = %v, want %v", got, tt.want)
+			}
+			mockRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -1,18 +1,35 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	commons "app/internal/commons"
 	handler "app/internal/handlers"
+	"app/internal/mailer"
+	"app/internal/oidc"
 	"app/internal/repositories"
+	apiKeyRepository "app/internal/repositories/apikey"
+	categoryRepository "app/internal/repositories/category"
 	commentRepository "app/internal/repositories/comment"
+	identityRepository "app/internal/repositories/identity"
+	loginAttemptRepository "app/internal/repositories/loginattempt"
+	mediaRepository "app/internal/repositories/media"
+	passwordResetRepository "app/internal/repositories/passwordreset"
 	postRepository "app/internal/repositories/post"
+	recoveryCodeRepository "app/internal/repositories/recoverycode"
+	sessionRepository "app/internal/repositories/session"
+	tagRepository "app/internal/repositories/tag"
 	userRepository "app/internal/repositories/user"
+	"app/internal/search"
+	"app/internal/storage"
 	usecases "app/internal/usecases"
 
 	"github.com/gorilla/mux"
@@ -38,17 +55,51 @@ func main() {
 		port = "8080" // Default port if not set
 	}
 
-	lis, err := net.Listen("tcp", ":"+port)
 	timeoutContext := time.Duration(viper.GetInt("CONTEXT_TIMEOUT")) * time.Second
 
-	if err != nil {
-		log.Fatalf("failed listen: %v", err)
+	configJWT := commons.ConfigJWT{
+		SigningMethod:          viper.GetString("JWT_SIGNING_METHOD"),
+		SecretJWT:              viper.GetString("JWT_SECRET_KEY"),
+		Issuer:                 viper.GetString("JWT_ISSUER"),
+		Audience:               viper.GetString("JWT_AUDIENCE"),
+		ExpiresDuration:        viper.GetInt("JWT_ACCESS_TTL_MINUTES"),
+		RefreshExpiresDuration: viper.GetInt("JWT_REFRESH_EXPIRES_DURATION"),
+	}
+	if configJWT.Issuer == "" {
+		configJWT.Issuer = "blog-api"
+	}
+	if configJWT.Audience == "" {
+		configJWT.Audience = "blog-api"
+	}
+	// JWT_EXPIRES_DURATION is the access token lifetime in hours from before refresh tokens,
+	// it is still honored so existing deployments keep their lifetime
+	if hours := viper.GetInt("JWT_EXPIRES_DURATION"); configJWT.ExpiresDuration == 0 && hours != 0 {
+		log.Println("JWT_EXPIRES_DURATION is deprecated, set JWT_ACCESS_TTL_MINUTES instead")
+		configJWT.ExpiresDuration = hours * 60
+	}
+	if configJWT.ExpiresDuration == 0 {
+		configJWT.ExpiresDuration = 15 // Default access token lifetime in minutes
+	}
+	if configJWT.RefreshExpiresDuration == 0 {
+		configJWT.RefreshExpiresDuration = 24 * 7 // Default refresh token lifetime in hours
 	}
-	fmt.Println("Server running on port " + viper.GetString("SERVER_PORT"))
 
-	configJWT := commons.ConfigJWT{
-		SecretJWT:       viper.GetString("JWT_SECRET_KEY"),
-		ExpiresDuration: viper.GetInt("JWT_EXPIRES_DURATION"),
+	if configJWT.SigningMethod != "" && configJWT.SigningMethod != commons.SigningAlgHS256 {
+		rotationInterval := time.Duration(viper.GetInt("JWT_KEY_ROTATION_INTERVAL")) * time.Hour
+		if rotationInterval == 0 {
+			rotationInterval = 30 * 24 * time.Hour // Default signing key lifetime
+		}
+		retention := time.Duration(viper.GetInt("JWT_KEY_RETENTION")) * time.Hour
+		if retention == 0 {
+			retention = 48 * time.Hour // Must outlive every token signed with a retired key
+		}
+
+		keys, err := commons.NewKeyManager(commons.NewFileKeyStore(viper.GetString("JWT_KEYS_DIR")), configJWT.SigningMethod, rotationInterval, retention)
+		if err != nil {
+			log.Fatalf("failed to load signing keys: %v", err)
+		}
+		keys.Start(context.Background(), time.Minute)
+		configJWT.Keys = keys
 	}
 
 	configDB := repositories.DBConfig{
@@ -59,32 +110,345 @@ func main() {
 		Name:     viper.GetString("DB_NAME"),
 	}
 
+	mail, err := mailer.NewMailer(mailer.Config{
+		Driver:       viper.GetString("MAILER_DRIVER"),
+		From:         viper.GetString("MAILER_FROM"),
+		OutboxDir:    viper.GetString("MAILER_OUTBOX_DIR"),
+		SMTPHost:     viper.GetString("SMTP_HOST"),
+		SMTPPort:     viper.GetString("SMTP_PORT"),
+		SMTPUsername: viper.GetString("SMTP_USERNAME"),
+		SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+	})
+	if err != nil {
+		log.Fatalf("failed to init mailer: %v", err)
+	}
+
+	mediaStorage, err := storage.NewStorage(storage.Config{
+		Driver:            viper.GetString("MEDIA_STORAGE_DRIVER"),
+		PublicURL:         viper.GetString("MEDIA_PUBLIC_URL"),
+		LocalDir:          viper.GetString("MEDIA_LOCAL_DIR"),
+		S3Endpoint:        viper.GetString("S3_ENDPOINT"),
+		S3Region:          viper.GetString("S3_REGION"),
+		S3Bucket:          viper.GetString("S3_BUCKET"),
+		S3AccessKeyID:     viper.GetString("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: viper.GetString("S3_SECRET_ACCESS_KEY"),
+	})
+	if err != nil {
+		log.Fatalf("failed to init media storage: %v", err)
+	}
+
+	configMedia := usecases.MediaConfig{
+		MaxSize:   int64(viper.GetInt("MEDIA_MAX_SIZE_MB")) << 20,
+		UserQuota: int64(viper.GetInt("MEDIA_USER_QUOTA_MB")) << 20,
+	}
+	if configMedia.MaxSize == 0 {
+		configMedia.MaxSize = 10 << 20 // Default upload limit of 10 MB
+	}
+	if configMedia.UserQuota == 0 {
+		configMedia.UserQuota = 100 << 20 // Default quota of 100 MB per user
+	}
+
+	configPasswordReset := usecases.PasswordResetConfig{
+		ResetURL:        viper.GetString("PASSWORD_RESET_URL"),
+		ExpiresDuration: viper.GetInt("PASSWORD_RESET_EXPIRES_DURATION"),
+	}
+	if configPasswordReset.ExpiresDuration == 0 {
+		configPasswordReset.ExpiresDuration = 30 // Default reset token lifetime in minutes
+	}
+
+	configEmailVerification := usecases.EmailVerificationConfig{
+		VerifyURL:       viper.GetString("EMAIL_VERIFY_URL"),
+		ExpiresDuration: viper.GetInt("EMAIL_VERIFY_EXPIRES_DURATION"),
+		Required:        viper.GetBool("EMAIL_VERIFICATION_REQUIRED"),
+	}
+	if configEmailVerification.VerifyURL == "" {
+		configEmailVerification.VerifyURL = "http://localhost:" + port + "/verify-email"
+	}
+	if configEmailVerification.ExpiresDuration == 0 {
+		configEmailVerification.ExpiresDuration = 24 // Default verification link lifetime in hours
+	}
+
+	configLoginGuard := usecases.LoginGuardConfig{
+		MaxFailures:      viper.GetInt("LOGIN_MAX_FAILURES"),
+		MaxFailuresPerIP: viper.GetInt("LOGIN_MAX_FAILURES_PER_IP"),
+		LockoutDuration:  time.Duration(viper.GetInt("LOGIN_LOCKOUT_DURATION")) * time.Minute,
+		BackoffBase:      time.Duration(viper.GetInt("LOGIN_BACKOFF_BASE")) * time.Second,
+		BackoffMax:       time.Duration(viper.GetInt("LOGIN_BACKOFF_MAX")) * time.Second,
+	}
+	if configLoginGuard.MaxFailures == 0 {
+		configLoginGuard.MaxFailures = 5
+	}
+	if configLoginGuard.MaxFailuresPerIP == 0 {
+		configLoginGuard.MaxFailuresPerIP = 50
+	}
+	if configLoginGuard.LockoutDuration == 0 {
+		configLoginGuard.LockoutDuration = 15 * time.Minute
+	}
+	if configLoginGuard.BackoffBase == 0 {
+		configLoginGuard.BackoffBase = time.Second
+	}
+	if configLoginGuard.BackoffMax == 0 {
+		configLoginGuard.BackoffMax = time.Minute
+	}
+
+	configTwoFactor := usecases.TwoFactorConfig{
+		Issuer:            viper.GetString("TWO_FACTOR_ISSUER"),
+		ChallengeDuration: time.Duration(viper.GetInt("TWO_FACTOR_CHALLENGE_DURATION")) * time.Minute,
+	}
+	if configTwoFactor.Issuer == "" {
+		configTwoFactor.Issuer = "Blog"
+	}
+	if configTwoFactor.ChallengeDuration == 0 {
+		configTwoFactor.ChallengeDuration = 5 * time.Minute
+	}
+
+	accountDeletionPolicy := viper.GetString("ACCOUNT_DELETION_CONTENT_POLICY")
+	if accountDeletionPolicy == "" {
+		accountDeletionPolicy = usecases.DeletionPolicyAnonymize
+	}
+	if accountDeletionPolicy != usecases.DeletionPolicyAnonymize && accountDeletionPolicy != usecases.DeletionPolicyDelete {
+		log.Fatalf("unknown ACCOUNT_DELETION_CONTENT_POLICY %q", accountDeletionPolicy)
+	}
+
+	postSchedulerInterval := time.Duration(viper.GetInt("POST_SCHEDULER_INTERVAL")) * time.Second
+	if postSchedulerInterval == 0 {
+		postSchedulerInterval = 30 * time.Second
+	}
+	// Trashed posts are purged after TRASH_RETENTION_DAYS, checked every TRASH_PURGE_INTERVAL seconds
+	trashRetention := time.Duration(viper.GetInt("TRASH_RETENTION_DAYS")) * 24 * time.Hour
+	if trashRetention == 0 {
+		trashRetention = 30 * 24 * time.Hour
+	}
+	trashPurgeInterval := time.Duration(viper.GetInt("TRASH_PURGE_INTERVAL")) * time.Second
+	if trashPurgeInterval == 0 {
+		trashPurgeInterval = time.Hour
+	}
+	shutdownTimeout := time.Duration(viper.GetInt("SHUTDOWN_TIMEOUT")) * time.Second
+	if shutdownTimeout == 0 {
+		shutdownTimeout = 30 * time.Second
+	}
+
+	// Zero values fall back to the defaults, so only the parameters being tuned need to be set
+	argon2Params := commons.DefaultArgon2Params()
+	if memory := viper.GetUint32("PASSWORD_ARGON2_MEMORY"); memory != 0 {
+		argon2Params.Memory = memory
+	}
+	if iterations := viper.GetUint32("PASSWORD_ARGON2_ITERATIONS"); iterations != 0 {
+		argon2Params.Iterations = iterations
+	}
+	if parallelism := viper.GetUint("PASSWORD_ARGON2_PARALLELISM"); parallelism != 0 {
+		argon2Params.Parallelism = uint8(parallelism)
+	}
+	hasher := commons.NewPasswordHasher(argon2Params)
+
 	db := repositories.InitDB(configDB)
 	userRepo := userRepository.NewUserRepository(db, timeoutContext)
-	userUsecase := usecases.NewUserUsecase(userRepo, configJWT, timeoutContext)
-	userHandler := handler.NewUserHandler(userUsecase)
+	sessionRepo := sessionRepository.NewSessionRepository(db, timeoutContext)
+	emailVerificationUsecase := usecases.NewEmailVerificationUsecase(userRepo, mail, configJWT, configEmailVerification, timeoutContext)
+	var loginAttemptRepo loginAttemptRepository.LoginAttemptRepository
+	if viper.GetString("LOGIN_ATTEMPT_STORE") == "memory" {
+		loginAttemptRepo = loginAttemptRepository.NewMemoryLoginAttemptRepository()
+	} else {
+		loginAttemptRepo = loginAttemptRepository.NewLoginAttemptRepository(db, timeoutContext)
+	}
+	loginGuard := usecases.NewLoginGuard(loginAttemptRepo, configLoginGuard)
+	recoveryCodeRepo := recoveryCodeRepository.NewRecoveryCodeRepository(db, timeoutContext)
+	twoFactorUsecase := usecases.NewTwoFactorUsecase(userRepo, recoveryCodeRepo, loginGuard, configJWT, configTwoFactor, timeoutContext)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorUsecase)
+	userUsecase := usecases.NewUserUsecase(userRepo, sessionRepo, emailVerificationUsecase, loginGuard, twoFactorUsecase, hasher, configJWT, timeoutContext)
+	userHandler := handler.NewUserHandler(userUsecase, emailVerificationUsecase)
+	configJWT.Sessions = userUsecase
+
+	// Run a maintenance subcommand instead of the server, e.g. `go run . promote -email ... -role admin`
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], userRepo, userUsecase); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	passwordResetRepo := passwordResetRepository.NewPasswordResetRepository(db, timeoutContext)
+	passwordUsecase := usecases.NewPasswordUsecase(userRepo, sessionRepo, passwordResetRepo, hasher, mail, configPasswordReset, timeoutContext)
+	passwordHandler := handler.NewPasswordHandler(passwordUsecase)
+
+	searchDriver := viper.GetString("SEARCH_DRIVER")
+	searchIndex, err := search.NewIndex(searchDriver, db, timeoutContext)
+	if err != nil {
+		log.Fatalf("failed to init search: %v", err)
+	}
 
 	postRepo := postRepository.NewPostRepository(db, timeoutContext)
-	postUsecase := usecases.NewPostUsecase(postRepo, userRepo, timeoutContext)
+	categoryRepo := categoryRepository.NewCategoryRepository(db, timeoutContext)
+	mediaRepo := mediaRepository.NewMediaRepository(db, timeoutContext)
+	postUsecase := usecases.NewPostUsecase(postRepo, categoryRepo, mediaRepo, userRepo, searchIndex, configEmailVerification.Required, timeoutContext)
 	postHandler := handler.NewPostHandler(postUsecase)
+	if n, err := postUsecase.BackfillSlugs(context.Background()); err != nil {
+		log.Printf("failed to backfill post slugs: %v", err)
+	} else if n > 0 {
+		log.Printf("generated slugs for %d posts", n)
+	}
+	if n, err := postUsecase.BackfillContentHTML(context.Background()); err != nil {
+		log.Printf("failed to render post contents: %v", err)
+	} else if n > 0 {
+		log.Printf("rendered the content of %d posts", n)
+	}
+
+	mediaUsecase := usecases.NewMediaUsecase(mediaRepo, mediaStorage, configMedia, timeoutContext)
+	mediaHandler := handler.NewMediaHandler(mediaUsecase, configMedia.MaxSize)
+
+	tagRepo := tagRepository.NewTagRepository(db, timeoutContext)
+	taxonomyUsecase := usecases.NewTaxonomyUsecase(tagRepo, categoryRepo, timeoutContext)
+	taxonomyHandler := handler.NewTaxonomyHandler(taxonomyUsecase)
+
+	profileUsecase := usecases.NewProfileUsecase(userRepo, postRepo, timeoutContext)
+	profileHandler := handler.NewProfileHandler(profileUsecase)
 
 	commentRepo := commentRepository.NewCommentRepository(db, timeoutContext)
-	commentUsecase := usecases.NewCommentUsecase(commentRepo, postRepo, userRepo, timeoutContext)
+	commentUsecase := usecases.NewCommentUsecase(commentRepo, postRepo, userRepo, searchIndex, configEmailVerification.Required, timeoutContext)
 	commentHandler := handler.NewCommentHandler(commentUsecase)
+	if n, err := commentUsecase.BackfillContentHTML(context.Background()); err != nil {
+		log.Printf("failed to render comment contents: %v", err)
+	} else if n > 0 {
+		log.Printf("rendered the content of %d comments", n)
+	}
+
+	searchUsecase := usecases.NewSearchUsecase(searchIndex, postRepo, commentRepo, timeoutContext)
+	searchHandler := handler.NewSearchHandler(searchUsecase)
+	// The in-memory index starts empty, the MySQL one is the database itself
+	if searchDriver == "memory" {
+		if n, err := searchUsecase.Reindex(context.Background()); err != nil {
+			log.Fatalf("failed to build the search index: %v", err)
+		} else {
+			log.Printf("indexed %d posts and comments for search", n)
+		}
+	}
+
+	accountUsecase := usecases.NewAccountUsecase(userRepo, postRepo, commentRepo, searchIndex, mediaStorage, hasher, loginGuard, accountDeletionPolicy, timeoutContext)
+	accountHandler := handler.NewAccountHandler(accountUsecase)
+
+	apiKeyRepo := apiKeyRepository.NewAPIKeyRepository(db, timeoutContext)
+	apiKeyUsecase := usecases.NewAPIKeyUsecase(apiKeyRepo, userRepo, timeoutContext)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyUsecase)
+	configJWT.APIKeys = apiKeyUsecase
+
+	// Sign-in through an OpenID Connect provider is only offered when an issuer is configured
+	var oidcHandler *handler.OIDCHandler
+	if issuer := viper.GetString("OIDC_ISSUER"); issuer != "" {
+		configOIDC := oidc.Config{
+			Issuer:       issuer,
+			ClientID:     viper.GetString("OIDC_CLIENT_ID"),
+			ClientSecret: viper.GetString("OIDC_CLIENT_SECRET"),
+			RedirectURL:  viper.GetString("OIDC_REDIRECT_URL"),
+			Scopes:       viper.GetStringSlice("OIDC_SCOPES"),
+		}
+		if configOIDC.RedirectURL == "" {
+			configOIDC.RedirectURL = "http://localhost:" + port + "/auth/oidc/callback"
+		}
+		provider, err := oidc.NewProvider(context.Background(), configOIDC)
+		if err != nil {
+			log.Fatalf("failed to init oidc provider: %v", err)
+		}
+
+		identityRepo := identityRepository.NewIdentityRepository(db, timeoutContext)
+		oidcUsecase := usecases.NewOIDCUsecase(provider, identityRepo, userRepo, userUsecase, configJWT, timeoutContext)
+		oidcHandler = handler.NewOIDCHandler(oidcUsecase)
+	}
 
 	r := mux.NewRouter()
 
+	r.HandleFunc("/.well-known/jwks.json", configJWT.JWKSHandler).Methods("GET")
+
 	r.HandleFunc("/register", userHandler.Register).Methods("POST")
 	r.HandleFunc("/login", userHandler.Login).Methods("POST")
+	r.HandleFunc("/login/2fa", userHandler.LoginTwoFactor).Methods("POST")
+	if oidcHandler != nil {
+		r.HandleFunc("/auth/oidc/login", oidcHandler.Login).Methods("GET")
+		r.HandleFunc("/auth/oidc/callback", oidcHandler.Callback).Methods("GET")
+	}
+	r.HandleFunc("/token/refresh", userHandler.RefreshToken).Methods("POST")
+	r.HandleFunc("/logout", configJWT.JWTMiddleware(userHandler.Logout)).Methods("POST")
+	r.HandleFunc("/logout-all", configJWT.JWTMiddleware(userHandler.LogoutAll)).Methods("POST")
+	r.HandleFunc("/me", configJWT.JWTMiddleware(profileHandler.GetMe)).Methods("GET")
+	r.HandleFunc("/me", configJWT.JWTMiddleware(profileHandler.UpdateMe)).Methods("PATCH")
+	r.HandleFunc("/me", configJWT.JWTMiddleware(accountHandler.Delete)).Methods("DELETE")
+	r.HandleFunc("/me/posts", configJWT.JWTMiddleware(postHandler.GetMyPosts)).Methods("GET")
+	r.HandleFunc("/me/trash", configJWT.JWTMiddleware(postHandler.GetMyTrash)).Methods("GET")
+	r.HandleFunc("/me/media", configJWT.JWTMiddleware(mediaHandler.GetMyMedia)).Methods("GET")
+	r.HandleFunc("/me/export", configJWT.JWTMiddleware(accountHandler.Export)).Methods("GET")
+	r.HandleFunc("/users/{id}", profileHandler.GetProfile).Methods("GET")
+	r.HandleFunc("/me/password", configJWT.JWTMiddleware(userHandler.UpdatePassword)).Methods("PUT")
+	r.HandleFunc("/me/api-keys", configJWT.JWTMiddleware(apiKeyHandler.CreateAPIKey)).Methods("POST")
+	r.HandleFunc("/me/api-keys", configJWT.JWTMiddleware(apiKeyHandler.ListAPIKeys)).Methods("GET")
+	r.HandleFunc("/me/api-keys/{id}", configJWT.JWTMiddleware(apiKeyHandler.RevokeAPIKey)).Methods("DELETE")
+	r.HandleFunc("/me/2fa/enroll", configJWT.JWTMiddleware(twoFactorHandler.Enroll)).Methods("POST")
+	r.HandleFunc("/me/2fa/confirm", configJWT.JWTMiddleware(twoFactorHandler.Confirm)).Methods("POST")
+	r.HandleFunc("/me/2fa/disable", configJWT.JWTMiddleware(twoFactorHandler.Disable)).Methods("POST")
+	r.HandleFunc("/me/2fa/recovery-codes", configJWT.JWTMiddleware(twoFactorHandler.RegenerateRecoveryCodes)).Methods("POST")
+	r.HandleFunc("/verify-email", userHandler.VerifyEmail).Methods("GET")
+	r.HandleFunc("/verify-email/resend", configJWT.JWTMiddleware(userHandler.ResendVerification)).Methods("POST")
+	r.HandleFunc("/password/forgot", passwordHandler.ForgotPassword).Methods("POST")
+	r.HandleFunc("/password/reset", passwordHandler.ResetPassword).Methods("POST")
+
+	r.HandleFunc("/admin/users/{id}/role", configJWT.JWTMiddleware(commons.RequirePermission(commons.PermManageUsers, userHandler.UpdateRole))).Methods("PUT")
+	r.HandleFunc("/admin/users/{id}/unlock", configJWT.JWTMiddleware(commons.RequirePermission(commons.PermManageUsers, userHandler.Unlock))).Methods("POST")
+	r.HandleFunc("/admin/categories", configJWT.JWTMiddleware(commons.RequirePermission(commons.PermManageCategories, taxonomyHandler.CreateCategory))).Methods("POST")
 
-	r.HandleFunc("/posts", configJWT.JWTMiddleware(postHandler.CreatePost)).Methods("POST")
+	// Changes to a post, comment or upload are up to its author, and to those whose role may
+	// change any, like admins for posts and moderators for comments. The usecases check again.
+	r.HandleFunc("/posts", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, postHandler.CreatePost)).Methods("POST")
 	r.HandleFunc("/posts", postHandler.GetAllPosts).Methods("GET")
-	r.HandleFunc("/posts/{id}", postHandler.GetPostByID).Methods("GET")
-	r.HandleFunc("/posts/{id}", configJWT.JWTMiddleware(postHandler.UpdatePost)).Methods("PUT")
-	r.HandleFunc("/posts/{id}", configJWT.JWTMiddleware(postHandler.DeletePost)).Methods("DELETE")
+	r.HandleFunc("/tags", taxonomyHandler.GetTags).Methods("GET")
+	r.HandleFunc("/categories", taxonomyHandler.GetCategories).Methods("GET")
+	r.HandleFunc("/search", searchHandler.Search).Methods("GET")
+	r.HandleFunc("/posts/{id}", configJWT.OptionalJWTMiddleware(postHandler.GetPostByID)).Methods("GET")
+	r.HandleFunc("/posts/by-slug/{slug}", configJWT.OptionalJWTMiddleware(postHandler.GetPostBySlug)).Methods("GET")
+	r.HandleFunc("/posts/{id}", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.UpdatePost))).Methods("PUT")
+	r.HandleFunc("/posts/{id}", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.PatchPost))).Methods("PATCH")
+	r.HandleFunc("/posts/{id}", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermDeleteAnyPost, postHandler.PostAuthor, postHandler.DeletePost))).Methods("DELETE")
+	r.HandleFunc("/posts/{id}/publish", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.PublishPost))).Methods("POST")
+	r.HandleFunc("/posts/{id}/unpublish", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.UnpublishPost))).Methods("POST")
+	r.HandleFunc("/posts/{id}/schedule", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.SchedulePost))).Methods("POST")
+	r.HandleFunc("/posts/{id}/archive", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.ArchivePost))).Methods("POST")
+	r.HandleFunc("/render/preview", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, postHandler.RenderPreview)).Methods("POST")
+	r.HandleFunc("/posts/{id}/restore", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermDeleteAnyPost, postHandler.TrashedPostAuthor, postHandler.RestorePost))).Methods("POST")
+	r.HandleFunc("/posts/{id}/revisions", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.GetRevisions))).Methods("GET")
+	r.HandleFunc("/posts/{id}/revisions/diff", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.DiffRevisions))).Methods("GET")
+	r.HandleFunc("/posts/{id}/revisions/{rev}/restore", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermUpdateAnyPost, postHandler.PostAuthor, postHandler.RestoreRevision))).Methods("POST")
 
-	r.HandleFunc("/posts/{id}/comments", configJWT.JWTMiddleware(commentHandler.CreateComment)).Methods("POST")
+	r.HandleFunc("/media", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, mediaHandler.Upload)).Methods("POST")
+	r.HandleFunc("/media/{id}", configJWT.APIKeyMiddleware(commons.ScopePostsWrite, commons.RequireOwnerOrPermission(commons.PermDeleteAnyPost, mediaHandler.MediaOwner, mediaHandler.DeleteMedia))).Methods("DELETE")
+	// Files in the local storage are served by the API itself, S3 serves its own
+	if local, ok := mediaStorage.(*storage.LocalStorage); ok {
+		r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", local.Handler())).Methods("GET", "HEAD")
+	}
+
+	r.HandleFunc("/posts/{id}/comments", configJWT.APIKeyMiddleware(commons.ScopeCommentsWrite, commentHandler.CreateComment)).Methods("POST")
 	r.HandleFunc("/posts/{id}/comments", commentHandler.GetCommentsByPostID).Methods("GET")
+	r.HandleFunc("/posts/{id}/comments/{commentId}", configJWT.APIKeyMiddleware(commons.ScopeCommentsWrite, commons.RequireOwnerOrPermission(commons.PermDeleteAnyComment, commentHandler.CommentAuthor, commentHandler.DeleteComment))).Methods("DELETE")
+
+	// SIGINT and SIGTERM stop the server and the background jobs gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	postScheduler := usecases.NewPostScheduler(postRepo, searchIndex, postSchedulerInterval)
+	schedulerDone := make(chan struct{})
+	go func() {
+		postScheduler.Run(ctx)
+		close(schedulerDone)
+	}()
+
+	postPurger := usecases.NewPostPurger(postRepo, searchIndex, trashRetention, trashPurgeInterval)
+	purgerDone := make(chan struct{})
+	go func() {
+		postPurger.Run(ctx)
+		close(purgerDone)
+	}()
+
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("failed listen: %v", err)
+	}
+	fmt.Println("Server running on port " + viper.GetString("SERVER_PORT"))
 
 	// Start the HTTP server
 	httpServer := &http.Server{
@@ -92,7 +456,23 @@ func main() {
 		Handler: r,
 	}
 
-	if err := httpServer.Serve(lis); err != nil {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(lis)
+	}()
+
+	select {
+	case err := <-serveErr:
 		log.Fatalf("failed to serve: %v", err)
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shut down the server gracefully: %v", err)
 	}
+	<-schedulerDone
+	<-purgerDone
 }